@@ -1,11 +1,15 @@
 package postgres
 
-import "github.com/jackc/pgconn"
+import (
+	"errors"
 
-// IsLockError checks if the error is a lock error
+	"github.com/jackc/pgconn"
+)
+
+// IsLockError checks if the error or any error it wraps is a lock error
 func IsLockError(err error) bool {
-	pgErr, ok := err.(*pgconn.PgError)
-	if !ok {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
 		return false
 	}
 
@@ -14,10 +18,10 @@ func IsLockError(err error) bool {
 	return pgErr.Code == "55P03"
 }
 
-// IsUniqueConstraintError checks if the error is a unique constraint error
+// IsUniqueConstraintError checks if the error or any error it wraps is a unique constraint error
 func IsUniqueConstraintError(err error) bool {
-	pgErr, ok := err.(*pgconn.PgError)
-	if !ok {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
 		return false
 	}
 
@@ -2,6 +2,7 @@ package postgres_test
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/jackc/pgconn"
@@ -21,6 +22,9 @@ func TestIsLockError(t *testing.T) {
 	// Test case: Error is a *pgconn.PgError with the lock error code
 	pgErr = &pgconn.PgError{Code: "55P03"}
 	assert.True(t, postgres.IsLockError(pgErr))
+
+	// Test case: Error wraps a *pgconn.PgError with the lock error code
+	assert.True(t, postgres.IsLockError(fmt.Errorf("wrapped: %w", pgErr)))
 }
 
 func TestIsUniqueConstraintError(t *testing.T) {
@@ -35,4 +39,7 @@ func TestIsUniqueConstraintError(t *testing.T) {
 	// Test case: Error is a *pgconn.PgError with the unique constraint error code
 	pgErr = &pgconn.PgError{Code: "23505"}
 	assert.True(t, postgres.IsUniqueConstraintError(pgErr))
+
+	// Test case: Error wraps a *pgconn.PgError with the unique constraint error code
+	assert.True(t, postgres.IsUniqueConstraintError(fmt.Errorf("wrapped: %w", pgErr)))
 }
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-go/statsd"
+	"github.com/wego/pkg/common"
+)
+
+// DatadogReporterName is the suggested name to register the DatadogReporter with
+const DatadogReporterName = "datadog"
+
+const defaultDatadogMetric = "errors.captured"
+
+// DatadogReporter reports errors to Datadog as events, and counts them with a metric. It uses the statsD client stored
+// in the context by common.SetStatsD, nothing is reported when there is none.
+type DatadogReporter struct {
+	// Metric is the name of the counter incremented for each error, "errors.captured" is used when it's empty
+	Metric string
+}
+
+// Report sends the error as a Datadog event & increments the error counter
+func (r DatadogReporter) Report(ctx context.Context, err error, level Level) {
+	client := common.GetStatsD(ctx)
+	if client == nil {
+		return
+	}
+
+	code := strconv.Itoa(Code(err))
+	ops := Ops(err)
+	tags := []string{SentryErrorCode + ":" + code, "level:" + string(level)}
+	if len(ops) > 0 {
+		tags = append(tags, "operation:"+string(ops[0]))
+	}
+
+	metric := r.Metric
+	if metric == "" {
+		metric = defaultDatadogMetric
+	}
+	_ = client.Incr(metric, tags, 1)
+
+	_ = client.Event(&statsd.Event{
+		Title:          datadogTitle(err, code, ops),
+		Text:           datadogText(err, ops),
+		AggregationKey: code + ":" + joinOps(ops),
+		AlertType:      datadogAlertType(level),
+		Tags:           tags,
+	})
+}
+
+func datadogTitle(err error, code string, ops []Op) string {
+	if len(ops) > 0 {
+		return fmt.Sprintf("[%s] %s", code, ops[0])
+	}
+	return fmt.Sprintf("[%s] %T", code, err)
+}
+
+func datadogText(err error, ops []Op) string {
+	var sb strings.Builder
+	_, _ = sb.WriteString(err.Error())
+	if len(ops) > 0 {
+		_, _ = sb.WriteString("\n" + SentryOperations + ": " + joinOps(ops))
+	}
+	writeJSON(&sb, ctxBasics, Basics(err))
+	writeJSON(&sb, ctxExtras, Extras(err))
+	return sb.String()
+}
+
+func writeJSON[M ~map[string]any](sb *strings.Builder, name string, values M) {
+	if len(values) == 0 {
+		return
+	}
+	if data, err := json.Marshal(values); err == nil {
+		_, _ = sb.WriteString("\n" + name + ": " + string(data))
+	}
+}
+
+func datadogAlertType(level Level) statsd.EventAlertType {
+	if level == LevelWarning {
+		return statsd.Warning
+	}
+	return statsd.Error
+}
+
+func joinOps(ops []Op) string {
+	s := make([]string, 0, len(ops))
+	for _, op := range ops {
+		s = append(s, string(op))
+	}
+	return strings.Join(s, " > ")
+}
@@ -19,11 +19,13 @@ type Kind int
 
 // Error custom error
 type Error struct {
-	Op   Op
-	Kind Kind
-	Err  error
-	msg  string
-	ctx  map[string]any
+	Op     Op
+	Kind   Kind
+	Err    error
+	msg    string
+	ctx    map[string]any
+	stack  []uintptr
+	fields map[Key]any
 }
 
 // error kinds
@@ -47,6 +49,13 @@ const (
 	ctxExtras = "extras"
 )
 
+// contextHolder is an error holding basics & extras, like Error & Multi
+type contextHolder interface {
+	error
+	basics() common.Basics
+	extras() common.Extras
+}
+
 // sentry keys
 const (
 	SentryErrorCode  = "error_code"
@@ -54,18 +63,61 @@ const (
 	SentryRequestID  = "request_id"
 )
 
+// sentinel errors, use them with the standard errors.Is to check the Kind of an error
 var (
+	// ErrBadRequest the request is invalid
+	ErrBadRequest = New(BadRequest, "bad request", NoStack)
+	// ErrConflict the request conflicts with the current state of the resource
+	ErrConflict = New(Conflict, "conflict", NoStack)
+	// ErrForbidden the requested action is not allowed
+	ErrForbidden = New(Forbidden, "forbidden", NoStack)
+	// ErrNotFound the requested resource is not found
+	ErrNotFound = New(NotFound, "not found", NoStack)
+	// ErrUnauthorized the request is not authenticated
+	ErrUnauthorized = New(Unauthorized, "unauthorized", NoStack)
+	// ErrUnprocessable the request is valid but cannot be processed
+	ErrUnprocessable = New(Unprocessable, "unprocessable", NoStack)
+	// ErrTooManyRequests the request is rate limited
+	ErrTooManyRequests = New(TooManyRequests, "too many requests", NoStack)
+	// ErrUnexpected an unexpected error happened
+	ErrUnexpected = New(Unexpected, "unexpected error", NoStack)
+	// ErrRetry the action should be retried
+	ErrRetry = New(Retry, "retry", NoStack)
 	// ErrNotSupported the requested action/resource is not supported
-	ErrNotSupported = New(NotSupported, "not supported")
+	ErrNotSupported = New(NotSupported, "not supported", NoStack)
 	// ErrNotImplemented the requested action/resource is not implemented
-	ErrNotImplemented = New(NotImplemented, "not implemented")
+	ErrNotImplemented = New(NotImplemented, "not implemented", NoStack)
 	// ErrNotModified the requested action/resource is not modified
-	ErrNotModified = New(NotModified, "not modified")
+	ErrNotModified = New(NotModified, "not modified", NoStack)
 )
 
-// New construct a new error, default having kind Unexpected
+// kindSentinels are the sentinel errors above, each of them matches any Error of its Kind.
+// Other Errors, e.g. the sentinels of the callers, only match themselves.
+var kindSentinels = map[*Error]struct{}{
+	ErrBadRequest:      {},
+	ErrConflict:        {},
+	ErrForbidden:       {},
+	ErrNotFound:        {},
+	ErrUnauthorized:    {},
+	ErrUnprocessable:   {},
+	ErrTooManyRequests: {},
+	ErrUnexpected:      {},
+	ErrRetry:           {},
+	ErrNotSupported:    {},
+	ErrNotImplemented:  {},
+	ErrNotModified:     {},
+}
+
+// New construct a new error, default having kind Unexpected.
+// The stack trace of the caller is recorded unless NoStack is passed as one of the args.
 func New(args ...interface{}) *Error {
+	return newError(1, args...)
+}
+
+// newError constructs a new error, skip is the number of frames between newError and the caller to record
+func newError(skip int, args ...interface{}) *Error {
 	e := &Error{}
+	withStack := true
 
 	for _, arg := range args {
 		switch arg := arg.(type) {
@@ -82,8 +134,14 @@ func New(args ...interface{}) *Error {
 			_ = e.WithContext(arg.(context.Context))
 		case string:
 			e.msg = arg
+		case StackMode:
+			withStack = arg != NoStack
 		}
 	}
+
+	if withStack {
+		e.stack = callers(skip + 1)
+	}
 	return e
 }
 
@@ -110,16 +168,45 @@ func (e *Error) WithContext(ctx context.Context) *Error {
 	return e
 }
 
-// Code return HTTP status code of the error
+// Code return HTTP status code of the error, which is the first non-zero Kind found in the chain.
+// The Kind of errors wrapping multiple errors, like Multi, is resolved by ResolveKind.
 func Code(err error) int {
-	e, ok := err.(*Error)
-	if !ok {
-		return int(Unexpected)
+	for err != nil {
+		switch e := err.(type) {
+		case *Error:
+			if e.Kind != 0 {
+				return int(e.Kind)
+			}
+		case interface{ Unwrap() []error }:
+			if kind := ResolveKind(e.Unwrap()); kind != 0 {
+				return int(kind)
+			}
+			return int(Unexpected)
+		}
+		err = goErrors.Unwrap(err)
+	}
+	return int(Unexpected)
+}
+
+// Unwrap returns the wrapped error, so the standard errors.Is & errors.As can traverse the chain
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches the target. The sentinels of this package, like ErrNotFound, match any Error of
+// the same Kind, any other Error target only matches itself.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t == nil {
+		return false
 	}
-	if e.Kind != 0 {
-		return int(e.Kind)
+
+	if e == t {
+		return true
 	}
-	return Code(e.Err)
+
+	_, sentinel := kindSentinels[t]
+	return sentinel && e.Kind == t.Kind
 }
 
 func (e *Error) Error() string {
@@ -140,39 +227,78 @@ func (e *Error) Error() string {
 	return msg
 }
 
+// Message returns the message of the error itself, without the messages of the wrapped errors
+func (e *Error) Message() string {
+	return e.msg
+}
+
 // ops return the stack of operation
 func ops(e *Error) []Op {
 	var res []Op
-	if e.Op != "" {
-		res = append(res, e.Op)
+	var err error = e
+	for goErrors.As(err, &e) {
+		if e.Op != "" {
+			res = append(res, e.Op)
+		}
+		err = e.Err
 	}
+	return res
+}
 
-	subErr, ok := e.Err.(*Error)
-	if !ok {
-		return res
+// Ops returns the stack of operations of the Errors in the chain, from the outermost to the innermost
+func Ops(err error) []Op {
+	var e *Error
+	if goErrors.As(err, &e) {
+		return ops(e)
+	}
+	return nil
+}
+
+// Basics returns the basics of the outermost Error in the chain, where the basics of the children are propagated to,
+// with the FieldTag fields in the chain
+func Basics(err error) common.Basics {
+	var basics common.Basics
+	var h contextHolder
+	if goErrors.As(err, &h) {
+		basics = h.basics()
 	}
+	return withFields(basics, err, FieldTag)
+}
 
-	res = append(res, ops(subErr)...)
-	return res
+// Extras returns the extras of the outermost Error in the chain, where the extras of the children are propagated to,
+// with the FieldExtra & FieldRedacted fields in the chain
+func Extras(err error) common.Extras {
+	var extras common.Extras
+	var h contextHolder
+	if goErrors.As(err, &h) {
+		extras = h.extras()
+	}
+	return withFields(extras, err, FieldExtra)
 }
 
-// WrapGORMError wraps an GORM error into our error such as adding errors.Kind
+// WrapGORMError wraps an GORM error into our error such as adding errors.Kind.
+// Database errors with a SQLSTATE code are classified by ClassifySQLState, with their details as extras.
 func WrapGORMError(op Op, err error) *Error {
 	if goErrors.Is(err, gorm.ErrRecordNotFound) {
-		return New(op, NotFound, err)
+		return newError(1, op, NotFound, err)
+	}
+
+	var sqlErr sqlStateError
+	if goErrors.As(err, &sqlErr) {
+		return newError(1, op, ClassifySQLState(sqlErr.SQLState()), err, sqlContext(sqlErr))
 	}
 
 	if goErrors.Is(err, gorm.ErrDuplicatedKey) {
-		return New(op, Conflict, err)
+		return newError(1, op, Conflict, err)
 	}
 
-	return New(op, err)
+	return newError(1, op, err)
 }
 
 // propagateContexts combines the "basics" and "extras" contexts from the child error into the parent, so that the
 // key-values propagate upwards to the top-level error.
 func (e *Error) propagateContexts() {
-	subErr, ok := e.Err.(*Error)
+	subErr, ok := e.Err.(contextHolder)
 	if !ok {
 		return
 	}
@@ -204,7 +330,10 @@ func (e *Error) propagateContexts() {
 	collection.Copy(extras, subExtras)
 	e.setExtras(extras)
 
-	subErr.ctx = nil
+	// a Multi error keeps its merged contexts, since its errors may be shared
+	if subErr, ok := subErr.(*Error); ok {
+		subErr.ctx = nil
+	}
 }
 
 func (e *Error) basics() common.Basics {
@@ -2,11 +2,15 @@ package errors
 
 import (
 	"context"
+	goErrors "errors"
+	"fmt"
+	"runtime"
 	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/wego/pkg/common"
+	"gorm.io/gorm"
 )
 
 const (
@@ -20,6 +24,20 @@ const (
 
 type testContextKey string
 
+// withoutStack returns a copy of the error chain with the recorded stack traces removed, so errors can be compared
+func withoutStack(err *Error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	cp := *err
+	cp.stack = nil
+	if child, ok := err.Err.(*Error); ok {
+		cp.Err = withoutStack(child)
+	}
+	return &cp
+}
+
 // Custom context type that implements context.Context interface
 type customContext struct {
 	context.Context
@@ -84,7 +102,7 @@ func TestNew(t *testing.T) {
 				},
 			}
 
-			assert.Equal(t, wantErr, err)
+			assert.Equal(t, wantErr, withoutStack(err))
 		}()
 	}
 
@@ -137,7 +155,7 @@ func TestNew_NestedContext_ParentContext(t *testing.T) {
 		},
 	}
 
-	assert.Equal(wantErr, err)
+	assert.Equal(wantErr, withoutStack(err))
 }
 
 func TestNew_NestedContext_ChildsOwnContext(t *testing.T) {
@@ -183,7 +201,7 @@ func TestNew_NestedContext_ChildsOwnContext(t *testing.T) {
 		},
 	}
 
-	assert.Equal(wantErr, err)
+	assert.Equal(wantErr, withoutStack(err))
 }
 
 func TestNew_NestedContext_NilChildContext(t *testing.T) {
@@ -211,7 +229,7 @@ func TestNew_NestedContext_NilChildContext(t *testing.T) {
 		},
 	}
 
-	assert.Equal(wantErr, err)
+	assert.Equal(wantErr, withoutStack(err))
 }
 
 func Test_basics(t *testing.T) {
@@ -562,3 +580,188 @@ func TestError_ContextHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestNew_StackTrace(t *testing.T) {
+	assert := assert.New(t)
+
+	err := New(Op(childOp), childErrMsg)
+	stack := err.StackTrace()
+	assert.NotEmpty(stack)
+
+	frame, _ := runtime.CallersFrames(stack).Next()
+	assert.Equal("github.com/wego/pkg/errors.TestNew_StackTrace", frame.Function)
+}
+
+func TestNew_NoStack(t *testing.T) {
+	assert := assert.New(t)
+
+	err := New(Op(childOp), childErrMsg, NoStack)
+	assert.Nil(err.StackTrace())
+	assert.Nil(ErrNotSupported.StackTrace())
+}
+
+func TestWrapGORMError_StackTrace(t *testing.T) {
+	assert := assert.New(t)
+
+	err := WrapGORMError(Op(childOp), gorm.ErrRecordNotFound)
+	assert.Equal(NotFound, err.Kind)
+
+	frame, _ := runtime.CallersFrames(err.StackTrace()).Next()
+	assert.Equal("github.com/wego/pkg/errors.TestWrapGORMError_StackTrace", frame.Function)
+}
+
+func Test_innermostStack(t *testing.T) {
+	assert := assert.New(t)
+
+	grandchildErr := New(Op(grandchildOp), grandchildErrMsg)
+	childErr := New(Op(childOp), childErrMsg, grandchildErr, NoStack)
+	err := New(Op(parentOp), parentErrMsg, childErr)
+
+	assert.Equal(grandchildErr.StackTrace(), innermostStack(err))
+
+	wrapped := New(Op(parentOp), goErrors.New(parentErrMsg))
+	assert.Equal(wrapped.StackTrace(), innermostStack(wrapped))
+	assert.Nil(innermostStack(goErrors.New(parentErrMsg)))
+}
+
+type testCauseError struct {
+	code string
+}
+
+func (e *testCauseError) Error() string {
+	return "cause " + e.code
+}
+
+func TestError_Unwrap(t *testing.T) {
+	assert := assert.New(t)
+
+	cause := goErrors.New(childErrMsg)
+	err := New(Op(parentOp), parentErrMsg, cause)
+	assert.Equal(cause, err.Unwrap())
+	assert.Nil(New(Op(parentOp)).Unwrap())
+}
+
+func TestError_Is(t *testing.T) {
+	assert := assert.New(t)
+
+	notFound := New(Op(childOp), NotFound, childErrMsg)
+	err := New(Op(parentOp), parentErrMsg, fmt.Errorf("wrapped: %w", notFound))
+
+	assert.True(goErrors.Is(err, ErrNotFound))
+	assert.True(goErrors.Is(err, notFound))
+	assert.False(goErrors.Is(err, ErrConflict))
+	assert.False(goErrors.Is(err, New(NotFound, Op(childOp))))
+
+	gormErr := WrapGORMError(Op(parentOp), gorm.ErrRecordNotFound)
+	assert.True(goErrors.Is(New(Op(parentOp), gormErr), gorm.ErrRecordNotFound))
+	assert.True(goErrors.Is(gormErr, ErrNotFound))
+}
+
+func TestError_Is_CallerSentinels(t *testing.T) {
+	assert := assert.New(t)
+
+	errBookingNotFound := New(NotFound, "booking not found", NoStack)
+	errUserNotFound := New(NotFound, "user not found", NoStack)
+
+	assert.False(goErrors.Is(errBookingNotFound, errUserNotFound))
+	assert.False(goErrors.Is(errUserNotFound, errBookingNotFound))
+	assert.False(goErrors.Is(New(Op(parentOp), NotFound, childErrMsg), errUserNotFound))
+
+	err := New(Op(parentOp), fmt.Errorf("wrapped: %w", errBookingNotFound))
+	assert.True(goErrors.Is(err, errBookingNotFound))
+	assert.False(goErrors.Is(err, errUserNotFound))
+	assert.True(goErrors.Is(err, ErrNotFound))
+}
+
+func TestError_As(t *testing.T) {
+	assert := assert.New(t)
+
+	cause := &testCauseError{code: "55P03"}
+	err := New(Op(parentOp), New(Op(childOp), BadRequest, cause))
+
+	var target *testCauseError
+	if assert.True(goErrors.As(err, &target)) {
+		assert.Equal(cause, target)
+	}
+
+	var e *Error
+	if assert.True(goErrors.As(fmt.Errorf("wrapped: %w", err), &e)) {
+		assert.Equal(err, e)
+	}
+}
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "non Error",
+			err:  goErrors.New(parentErrMsg),
+			want: int(Unexpected),
+		},
+		{
+			name: "Error without kind",
+			err:  New(Op(parentOp), parentErrMsg),
+			want: int(Unexpected),
+		},
+		{
+			name: "Error with kind",
+			err:  New(Op(parentOp), NotFound),
+			want: int(NotFound),
+		},
+		{
+			name: "kind from child Error",
+			err:  New(Op(parentOp), New(Op(childOp), Conflict)),
+			want: int(Conflict),
+		},
+		{
+			name: "kind from Error wrapped by fmt",
+			err:  New(Op(parentOp), fmt.Errorf("wrapped: %w", New(Op(childOp), Forbidden))),
+			want: int(Forbidden),
+		},
+		{
+			name: "Error wrapped by fmt",
+			err:  fmt.Errorf("wrapped: %w", New(Op(childOp), Retry)),
+			want: int(Retry),
+		},
+		{
+			name: "outer kind wins",
+			err:  New(Op(parentOp), BadRequest, New(Op(childOp), NotFound)),
+			want: int(BadRequest),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Code(tt.err))
+		})
+	}
+}
+
+func Test_ops(t *testing.T) {
+	assert := assert.New(t)
+
+	grandchildErr := New(Op(grandchildOp), grandchildErrMsg)
+	childErr := New(fmt.Errorf("wrapped: %w", grandchildErr))
+	err := New(Op(parentOp), childErr)
+
+	assert.Equal([]Op{parentOp, grandchildOp}, ops(err))
+	assert.Equal([]Op{parentOp, grandchildOp}, Ops(fmt.Errorf("wrapped: %w", err)))
+	assert.Nil(Ops(goErrors.New(parentErrMsg)))
+}
+
+func TestBasicsAndExtras(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := common.SetBasic(context.Background(), "key1", "value1")
+	ctx = common.SetExtra(ctx, "key2", "value2")
+	childErr := New(Op(childOp), childErrMsg, ctx)
+	err := fmt.Errorf("wrapped: %w", New(Op(parentOp), childErr))
+
+	assert.Equal(common.Basics{"key1": "value1"}, Basics(err))
+	assert.Equal(common.Extras{"key2": "value2"}, Extras(err))
+	assert.Nil(Basics(goErrors.New(parentErrMsg)))
+	assert.Nil(Extras(New(Op(parentOp))))
+}
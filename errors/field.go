@@ -0,0 +1,181 @@
+package errors
+
+import (
+	"context"
+	goErrors "errors"
+	"maps"
+)
+
+// Visibility decides how the value of a field is reported
+type Visibility int
+
+// field visibilities
+const (
+	// FieldExtra reports the value as an extra, which is not searchable in Sentry
+	FieldExtra Visibility = iota
+	// FieldTag reports the value as a tag, which is searchable in Sentry. Never use it for PII.
+	FieldTag
+	// FieldRedacted reports the value as Redacted, e.g. for emails & phone numbers
+	FieldRedacted
+)
+
+// Redacted replaces the value of redacted fields when reported
+const Redacted = "[redacted]"
+
+// Key is the key of a field, implemented by *Field
+type Key interface {
+	Name() string
+	Visibility() Visibility
+}
+
+/*
+Field is a typed key of a value carried by errors. Fields are compared by identity instead of name, so declare them
+once as package variables, fields of different packages never collide even with the same name:
+
+	var Email = errors.NewField[string]("email", errors.FieldRedacted)
+
+	err := errors.With(errors.New(op, errors.NotFound), Email, "someone@wego.com")
+	email, ok := errors.Get(err, Email)
+*/
+type Field[T any] struct {
+	name       string
+	visibility Visibility
+}
+
+// NewField declares a field with the name it's reported as & its visibility
+func NewField[T any](name string, visibility Visibility) *Field[T] {
+	return &Field[T]{name: name, visibility: visibility}
+}
+
+// Name returns the name the field is reported as
+func (f *Field[T]) Name() string {
+	return f.name
+}
+
+// Visibility returns how the field is reported
+func (f *Field[T]) Visibility() Visibility {
+	return f.visibility
+}
+
+// With sets the value of the field on the error & returns the error, the type of the value is the type of the field.
+// A nil error or field is a no-op.
+func With[T any](e *Error, key *Field[T], value T) *Error {
+	if e == nil || key == nil {
+		return e
+	}
+
+	if e.fields == nil {
+		e.fields = map[Key]any{}
+	}
+	e.fields[key] = value
+	return e
+}
+
+// Get returns the value of the field from the outermost error in the chain that has it, including the errors wrapped by
+// Multi errors
+func Get[T any](err error, key *Field[T]) (value T, ok bool) {
+	walk(err, func(err error) bool {
+		var v any
+		if e, isError := err.(*Error); isError {
+			if v, ok = e.fields[key]; ok {
+				value, ok = v.(T)
+			}
+		}
+		return ok
+	})
+	return
+}
+
+// fields returns the values of all the fields in the chain, the outer error wins on the same key
+func fields(err error) map[Key]any {
+	res := map[Key]any{}
+	walk(err, func(err error) bool {
+		if e, ok := err.(*Error); ok {
+			for key, value := range e.fields {
+				if _, exists := res[key]; !exists {
+					res[key] = value
+				}
+			}
+		}
+		return false
+	})
+	return res
+}
+
+// withFields returns a copy of values with the fields of the visibility in the chain of err added, values is returned
+// as is when there is no such field
+func withFields[M ~map[string]any](values M, err error, visibility Visibility) M {
+	var res M
+	for key, value := range fields(err) {
+		switch key.Visibility() {
+		case visibility:
+		case FieldRedacted:
+			// redacted fields are reported as extras, so it's still visible that the value was set
+			if visibility != FieldExtra {
+				continue
+			}
+			value = Redacted
+		default:
+			continue
+		}
+
+		if res == nil {
+			res = maps.Clone(values)
+			if res == nil {
+				res = M{}
+			}
+		}
+		res[key.Name()] = value
+	}
+
+	if res == nil {
+		return values
+	}
+	return res
+}
+
+// walk visits the errors in the chain depth-first from the outermost one, including the errors wrapped by Multi
+// errors, until visit returns true
+func walk(err error, visit func(error) bool) bool {
+	for err != nil {
+		if visit(err) {
+			return true
+		}
+
+		switch wrapper := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, err := range wrapper.Unwrap() {
+				if walk(err, visit) {
+					return true
+				}
+			}
+			return false
+		default:
+			err = goErrors.Unwrap(err)
+		}
+	}
+	return false
+}
+
+type contextKey struct {
+	name string
+}
+
+// requestIDKey is the key of the request ID in a context
+var requestIDKey = &contextKey{name: SentryRequestID}
+
+// WithRequestID returns a copy of the context with the request ID, which is reported as the SentryRequestID tag
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID stored in the context by WithRequestID
+func RequestID(ctx context.Context) (string, bool) {
+	if requestID, ok := ctx.Value(requestIDKey).(string); ok {
+		return requestID, true
+	}
+
+	// Deprecated: the untyped key is kept for the contexts set before WithRequestID is added
+	requestID, ok := ctx.Value(SentryRequestID).(string)
+	return requestID, ok
+}
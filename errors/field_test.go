@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/common"
+)
+
+var (
+	testBookingID = NewField[string]("booking_id", FieldTag)
+	testAttempts  = NewField[int]("attempts", FieldExtra)
+	testEmail     = NewField[string]("email", FieldRedacted)
+	// testOtherBookingID has the same name as testBookingID, but never collides with it
+	testOtherBookingID = NewField[string]("booking_id", FieldTag)
+)
+
+func TestGet(t *testing.T) {
+	assert := assert.New(t)
+
+	child := With(With(New(Op(childOp), NotFound), testBookingID, "child"), testAttempts, 3)
+	err := With(New(Op(parentOp), fmt.Errorf("wrapped: %w", child)), testBookingID, "parent")
+
+	bookingID, ok := Get(err, testBookingID)
+	assert.True(ok)
+	assert.Equal("parent", bookingID)
+
+	attempts, ok := Get(err, testAttempts)
+	assert.True(ok)
+	assert.Equal(3, attempts)
+
+	_, ok = Get(err, testOtherBookingID)
+	assert.False(ok)
+	_, ok = Get(goErrors.New(childErrMsg), testBookingID)
+	assert.False(ok)
+	_, ok = Get(nil, testBookingID)
+	assert.False(ok)
+}
+
+func TestGet_Multi(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMulti(Op(parentOp), New(NotFound), With(New(Conflict), testAttempts, 2))
+	attempts, ok := Get(New(Op(parentOp), m), testAttempts)
+	assert.True(ok)
+	assert.Equal(2, attempts)
+}
+
+func TestWith_Nil(t *testing.T) {
+	assert := assert.New(t)
+
+	err := With(New(NotFound), nil, 3)
+	_, ok := Get(err, testAttempts)
+	assert.False(ok)
+	assert.Nil(With(nil, testAttempts, 3))
+}
+
+func TestBasicsExtras_Fields(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := common.SetBasic(context.Background(), "key1", "value1")
+	ctx = common.SetExtra(ctx, "key2", "value2")
+	child := With(With(New(Op(childOp), NotFound, ctx), testEmail, "someone@wego.com"), testAttempts, 3)
+	err := With(New(Op(parentOp), child), testBookingID, "booking")
+
+	assert.Equal(common.Basics{"key1": "value1", "booking_id": "booking"}, Basics(err))
+	assert.Equal(common.Extras{"key2": "value2", "attempts": 3, "email": Redacted}, Extras(err))
+
+	// the contexts of the error are not modified
+	assert.Equal(common.Basics{"key1": "value1"}, err.basics())
+	assert.Equal(common.Extras{"key2": "value2"}, err.extras())
+}
+
+func Test_enrichScope_Fields(t *testing.T) {
+	assert := assert.New(t)
+
+	err := With(With(New(Op(parentOp), NotFound), testBookingID, "booking"), testEmail, "someone@wego.com")
+	ctx := WithRequestID(context.Background(), "request-id")
+
+	scope := sentry.NewScope()
+	enrichScope(ctx, scope, err)
+	event := scope.ApplyToEvent(sentry.NewEvent(), nil, nil)
+
+	assert.Equal(`"booking"`, event.Tags["booking_id"])
+	assert.Equal("request-id", event.Tags[SentryRequestID])
+	assert.NotContains(event.Tags, "email")
+	assert.Equal(Redacted, event.Extra["email"])
+}
+
+func TestRequestID(t *testing.T) {
+	assert := assert.New(t)
+
+	requestID, ok := RequestID(WithRequestID(context.Background(), "request-id"))
+	assert.True(ok)
+	assert.Equal("request-id", requestID)
+
+	_, ok = RequestID(context.Background())
+	assert.False(ok)
+}
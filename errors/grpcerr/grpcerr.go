@@ -0,0 +1,228 @@
+// Package grpcerr converts errors to gRPC statuses on the server side & back on the client side, so the Kind, the
+// operations, the basics & the extras of an error survive a gRPC call
+package grpcerr
+
+import (
+	"context"
+	"encoding/json"
+	goErrors "errors"
+	"strconv"
+
+	"github.com/wego/pkg/common"
+	"github.com/wego/pkg/env"
+	"github.com/wego/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	reason    = "WEGO_ERROR"
+	kindKey   = "kind"
+	basicsKey = "basics"
+	extrasKey = "extras"
+)
+
+var (
+	kindToCode = map[errors.Kind]codes.Code{
+		errors.NotModified:     codes.FailedPrecondition,
+		errors.BadRequest:      codes.InvalidArgument,
+		errors.Conflict:        codes.AlreadyExists,
+		errors.Forbidden:       codes.PermissionDenied,
+		errors.NotFound:        codes.NotFound,
+		errors.Unauthorized:    codes.Unauthenticated,
+		errors.Unprocessable:   codes.FailedPrecondition,
+		errors.TooManyRequests: codes.ResourceExhausted,
+		errors.Unexpected:      codes.Internal,
+		errors.Retry:           codes.Unavailable,
+		errors.NotSupported:    codes.Unimplemented,
+		errors.NotImplemented:  codes.Unimplemented,
+	}
+
+	codeToKind = map[codes.Code]errors.Kind{
+		codes.Canceled:           errors.Unexpected,
+		codes.Unknown:            errors.Unexpected,
+		codes.InvalidArgument:    errors.BadRequest,
+		codes.DeadlineExceeded:   errors.Retry,
+		codes.NotFound:           errors.NotFound,
+		codes.AlreadyExists:      errors.Conflict,
+		codes.PermissionDenied:   errors.Forbidden,
+		codes.ResourceExhausted:  errors.TooManyRequests,
+		codes.FailedPrecondition: errors.Unprocessable,
+		codes.Aborted:            errors.Conflict,
+		codes.OutOfRange:         errors.BadRequest,
+		codes.Unimplemented:      errors.NotImplemented,
+		codes.Internal:           errors.Unexpected,
+		codes.Unavailable:        errors.Retry,
+		codes.DataLoss:           errors.Unexpected,
+		codes.Unauthenticated:    errors.Unauthorized,
+	}
+)
+
+// CodeOf returns the gRPC code of a Kind, Unknown is returned for a Kind without mapping
+func CodeOf(kind errors.Kind) codes.Code {
+	if code, ok := kindToCode[kind]; ok {
+		return code
+	}
+	return codes.Unknown
+}
+
+// KindOf returns the Kind of a gRPC code, 0 is returned for OK
+func KindOf(code codes.Code) errors.Kind {
+	if code == codes.OK {
+		return 0
+	}
+	if kind, ok := codeToKind[code]; ok {
+		return kind
+	}
+	return errors.Unexpected
+}
+
+// Status converts an error into a gRPC status. The message of the status is the own message of the outermost Error,
+// like the detail of a problem, so the wrapped errors are never sent to the client. The exact Kind, the operations, the
+// basics & the extras are carried as the details of the status. Errors which are already gRPC statuses or context
+// errors are converted as is.
+func Status(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	var e *errors.Error
+	if !goErrors.As(err, &e) {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		return status.FromContextError(err)
+	}
+
+	kind := errors.Kind(errors.Code(err))
+	st := status.New(CodeOf(kind), e.Message())
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason:   reason,
+			Domain:   env.ServiceName(),
+			Metadata: map[string]string{kindKey: strconv.Itoa(int(kind))},
+		},
+	}
+
+	if ops := errors.Ops(err); len(ops) > 0 {
+		entries := make([]string, 0, len(ops))
+		for _, op := range ops {
+			entries = append(entries, string(op))
+		}
+		details = append(details, &errdetails.DebugInfo{StackEntries: entries})
+	}
+
+	if ctx := contexts(err); ctx != nil {
+		details = append(details, ctx)
+	}
+
+	if withDetails, detailErr := st.WithDetails(details...); detailErr == nil {
+		st = withDetails
+	}
+	return st
+}
+
+// FromStatus converts a gRPC status into an Error, restoring the Kind, the operations, the basics & the extras
+// carried in the details of the status
+func FromStatus(st *status.Status) *errors.Error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	kind := KindOf(st.Code())
+	var ops []string
+	ctx := context.Background()
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.GetReason() != reason {
+				continue
+			}
+			if k, err := strconv.Atoi(detail.GetMetadata()[kindKey]); err == nil {
+				kind = errors.Kind(k)
+			}
+		case *errdetails.DebugInfo:
+			ops = detail.GetStackEntries()
+		case *structpb.Struct:
+			ctx = withContexts(ctx, detail)
+		}
+	}
+
+	// the stack of this process is meaningless for a remote error, the remote operations are restored instead
+	args := []any{kind, st.Message(), ctx, errors.NoStack}
+	if len(ops) > 0 {
+		args = append(args, errors.Op(ops[len(ops)-1]))
+	}
+	err := errors.New(args...)
+
+	// restore the remote stack of operations, from the innermost to the outermost
+	for i := len(ops) - 2; i >= 0; i-- {
+		err = errors.New(errors.Op(ops[i]), err, errors.NoStack)
+	}
+	return err
+}
+
+// FromError converts an error returned by a gRPC call into an Error, errors which are not gRPC statuses like io.EOF
+// are returned as is
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	if e := FromStatus(st); e != nil {
+		return e
+	}
+	return nil
+}
+
+// contexts returns the basics & the extras of the error as a struct
+func contexts(err error) *structpb.Struct {
+	fields := map[string]any{}
+	if basics := errors.Basics(err); len(basics) > 0 {
+		fields[basicsKey] = basics
+	}
+	if extras := errors.Extras(err); len(extras) > 0 {
+		fields[extrasKey] = extras
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	// round trip through JSON so values of any type can be converted into a struct
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+
+	var values map[string]any
+	if err = json.Unmarshal(data, &values); err != nil {
+		return nil
+	}
+
+	s, err := structpb.NewStruct(values)
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+// withContexts returns a copy of the context with the basics & the extras from the struct added into it
+func withContexts(ctx context.Context, s *structpb.Struct) context.Context {
+	values := s.AsMap()
+	if basics, ok := values[basicsKey].(map[string]any); ok {
+		ctx = common.SetBasics(ctx, basics)
+	}
+	if extras, ok := values[extrasKey].(map[string]any); ok {
+		ctx = common.SetExtras(ctx, extras)
+	}
+	return ctx
+}
@@ -0,0 +1,148 @@
+package grpcerr_test
+
+import (
+	"context"
+	goErrors "errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/common"
+	"github.com/wego/pkg/errors"
+	"github.com/wego/pkg/errors/grpcerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testMethod = "/wego.Test/Get"
+
+func TestCodeOf(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(codes.NotFound, grpcerr.CodeOf(errors.NotFound))
+	assert.Equal(codes.Unavailable, grpcerr.CodeOf(errors.Retry))
+	assert.Equal(codes.Unimplemented, grpcerr.CodeOf(errors.NotSupported))
+	assert.Equal(codes.Unknown, grpcerr.CodeOf(errors.Kind(418)))
+}
+
+func TestKindOf(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(errors.Kind(0), grpcerr.KindOf(codes.OK))
+	assert.Equal(errors.BadRequest, grpcerr.KindOf(codes.InvalidArgument))
+	assert.Equal(errors.Retry, grpcerr.KindOf(codes.DeadlineExceeded))
+	assert.Equal(errors.Unexpected, grpcerr.KindOf(codes.Code(100)))
+}
+
+func TestStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(grpcerr.Status(nil))
+	assert.Equal(codes.PermissionDenied, grpcerr.Status(status.Error(codes.PermissionDenied, "denied")).Code())
+	assert.Equal(codes.DeadlineExceeded, grpcerr.Status(context.DeadlineExceeded).Code())
+	assert.Equal(codes.Unknown, grpcerr.Status(goErrors.New("boom")).Code())
+
+	st := grpcerr.Status(errors.New(errors.Op("op"), errors.NotFound, "booking not found"))
+	assert.Equal(codes.NotFound, st.Code())
+	assert.Equal("booking not found", st.Message())
+
+	err := errors.New(errors.Op("service.get"), "get booking failed", goErrors.New("pq: password authentication failed"))
+	assert.Equal("get booking failed", grpcerr.Status(err).Message(), "the wrapped errors are not sent")
+}
+
+func TestFromStatus_RoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := common.SetBasic(context.Background(), "site_code", "SG")
+	ctx = common.SetExtra(ctx, "booking_id", "123")
+	childErr := errors.New(errors.Op("repository.get"), errors.NotSupported, "currency not supported", ctx)
+	err := errors.New(errors.Op("service.get"), "get booking failed", childErr)
+
+	res := grpcerr.FromError(grpcerr.Status(err).Err())
+
+	assert.Equal("get booking failed", res.Error())
+	assert.Equal(int(errors.NotSupported), errors.Code(res))
+	assert.Equal([]errors.Op{"service.get", "repository.get"}, errors.Ops(res))
+	assert.Equal(common.Basics{"site_code": "SG"}, errors.Basics(res))
+	assert.Equal(common.Extras{"booking_id": "123"}, errors.Extras(res))
+
+	var e *errors.Error
+	for err := error(res); goErrors.As(err, &e); err = e.Err {
+		assert.Empty(e.StackTrace(), "no local stack is recorded for the remote operation %s", e.Op)
+	}
+}
+
+func TestFromStatus_ForeignStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	res := grpcerr.FromStatus(status.New(codes.Unavailable, "connection refused"))
+	assert.Equal("connection refused", res.Error())
+	assert.Equal(int(errors.Retry), errors.Code(res))
+	assert.Empty(errors.Ops(res))
+
+	assert.Nil(grpcerr.FromStatus(status.New(codes.OK, "")))
+	assert.Nil(grpcerr.FromError(nil))
+	assert.Equal(io.EOF, grpcerr.FromError(io.EOF))
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	assert := assert.New(t)
+
+	server := grpcerr.UnaryServerInterceptor()
+	client := grpcerr.UnaryClientInterceptor()
+
+	invoker := func(ctx context.Context, method string, req, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		_, err := server(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, any) (any, error) {
+			return nil, errors.New(errors.Op("handler"), errors.Conflict, "duplicated booking")
+		})
+		return err
+	}
+
+	err := client(context.Background(), testMethod, nil, nil, nil, invoker)
+	assert.Equal(int(errors.Conflict), errors.Code(err))
+	assert.Equal([]errors.Op{testMethod, "handler"}, errors.Ops(err))
+
+	resp, err := server(context.Background(), nil, nil, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(err)
+	assert.Equal("ok", resp)
+}
+
+type testClientStream struct {
+	grpc.ClientStream
+	err error
+}
+
+func (s *testClientStream) RecvMsg(any) error {
+	return s.err
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	assert := assert.New(t)
+
+	server := grpcerr.StreamServerInterceptor()
+	client := grpcerr.StreamClientInterceptor()
+
+	err := server(nil, nil, nil, func(any, grpc.ServerStream) error {
+		return errors.New(errors.Unauthorized, "expired token")
+	})
+	assert.Equal(codes.Unauthenticated, status.Code(err))
+
+	streamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &testClientStream{err: err}, nil
+	}
+	cs, err := client(context.Background(), &grpc.StreamDesc{}, nil, testMethod, streamer)
+	assert.NoError(err)
+
+	err = cs.RecvMsg(nil)
+	assert.Equal(int(errors.Unauthorized), errors.Code(err))
+	assert.Equal([]errors.Op{testMethod}, errors.Ops(err))
+
+	eofStreamer := func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &testClientStream{err: io.EOF}, nil
+	}
+	cs, _ = client(context.Background(), &grpc.StreamDesc{}, nil, testMethod, eofStreamer)
+	assert.Equal(io.EOF, cs.RecvMsg(nil))
+}
@@ -0,0 +1,82 @@
+package grpcerr
+
+import (
+	"context"
+
+	"github.com/wego/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// UnaryServerInterceptor returns a server interceptor converting the errors returned by unary handlers into statuses
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, Status(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor converting the errors returned by stream handlers into statuses
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return Status(err).Err()
+		}
+		return nil
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor converting the statuses returned by unary calls into Errors,
+// the full method name is added as the outermost operation
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return fromCallError(method, invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor converting the statuses returned by stream calls into Errors,
+// the full method name is added as the outermost operation
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, fromCallError(method, err)
+		}
+		return &clientStream{ClientStream: cs, method: method}, nil
+	}
+}
+
+// clientStream converts the statuses received from the stream into Errors
+type clientStream struct {
+	grpc.ClientStream
+	method string
+}
+
+// RecvMsg receives a message from the stream, io.EOF is returned as is when the stream completes successfully
+func (s *clientStream) RecvMsg(m any) error {
+	return fromCallError(s.method, s.ClientStream.RecvMsg(m))
+}
+
+func fromCallError(method string, err error) error {
+	err = FromError(err)
+	if e, ok := err.(*errors.Error); ok {
+		return errors.New(errors.Op(method), e)
+	}
+	return err
+}
@@ -0,0 +1,236 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wego/pkg/common"
+)
+
+const (
+	defaultSummaryInterval = time.Minute
+	// SuppressedCount is the extra key of a summary error holding the number of suppressed errors
+	SuppressedCount = "suppressed_count"
+)
+
+/*
+CaptureLimits configures the sampling & rate limiting of captured errors, so a flood of the same error does not burn the
+quota of the reporters.
+
+Errors are grouped by fingerprint, the same way as the Sentry fingerprint built by enrichScope: the error code plus a
+local stand-in of {{default}}, which is the operations & the location where the innermost Error was constructed.
+The errors suppressed by sampling or rate limiting are counted per fingerprint, and reported periodically as a single
+"N similar errors suppressed" error wrapping the last suppressed one.
+*/
+type CaptureLimits struct {
+	// Burst is the number of errors of the same fingerprint captured at once before being rate limited, errors are not
+	// rate limited when it's 0
+	Burst int
+	// Rate is the number of errors of the same fingerprint captured per second after the burst
+	Rate float64
+	// SampleRates are the rates, from 0 to 1, at which errors of a Kind are captured. Errors of other kinds are all
+	// captured.
+	SampleRates map[Kind]float64
+	// SummaryInterval is the interval to report the suppressed errors, 1 minute is used when it's 0
+	SummaryInterval time.Duration
+}
+
+var captureLimiter atomic.Pointer[limiter]
+
+// SetCaptureLimits sets the limits of captured errors, nil removes the limits.
+// The suppressed errors of the previous limits are reported before they are replaced.
+func SetCaptureLimits(limits *CaptureLimits) {
+	var l *limiter
+	if limits != nil {
+		l = newLimiter(*limits)
+	}
+
+	if previous := captureLimiter.Swap(l); previous != nil {
+		previous.stop()
+	}
+	if l != nil {
+		go l.run()
+	}
+}
+
+// bucket is the token bucket of a fingerprint
+type bucket struct {
+	tokens     float64
+	updatedAt  time.Time
+	suppressed int
+	last       error
+	basics     common.Basics
+	extras     common.Extras
+	level      Level
+}
+
+// summary of the suppressed errors of a fingerprint, with a copy of the contexts of the last one
+type summary struct {
+	count  int
+	last   error
+	basics common.Basics
+	extras common.Extras
+	level  Level
+}
+
+type limiter struct {
+	limits   CaptureLimits
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	now      func() time.Time
+	random   func() float64
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newLimiter(limits CaptureLimits) *limiter {
+	if limits.SummaryInterval <= 0 {
+		limits.SummaryInterval = defaultSummaryInterval
+	}
+
+	return &limiter{
+		limits:  limits,
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+		random:  rand.Float64,
+		done:    make(chan struct{}),
+	}
+}
+
+// allow reports whether the error should be captured, otherwise it's counted as suppressed
+func (l *limiter) allow(err error, level Level) bool {
+	key := fingerprintKey(err)
+	kind := Kind(Code(err))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limits.Burst), updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	if l.sampled(kind) && l.take(b, now) {
+		return true
+	}
+
+	b.suppressed++
+	b.last = err
+	// the contexts are copied in the goroutine of the caller, which still owns the error
+	b.basics = maps.Clone(Basics(err))
+	b.extras = maps.Clone(Extras(err))
+	b.level = level
+	return false
+}
+
+func (l *limiter) sampled(kind Kind) bool {
+	rate, ok := l.limits.SampleRates[kind]
+	return !ok || rate >= 1 || l.random() < rate
+}
+
+// take takes a token from the bucket after refilling it
+func (l *limiter) take(b *bucket, now time.Time) bool {
+	if l.limits.Burst <= 0 {
+		return true
+	}
+
+	l.refill(b, now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (l *limiter) refill(b *bucket, now time.Time) {
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	b.tokens = min(float64(l.limits.Burst), b.tokens+elapsed*l.limits.Rate)
+	b.updatedAt = now
+}
+
+func (l *limiter) run() {
+	ticker := time.NewTicker(l.limits.SummaryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.flush()
+		case <-l.done:
+			l.flush()
+			return
+		}
+	}
+}
+
+func (l *limiter) stop() {
+	l.stopOnce.Do(func() { close(l.done) })
+}
+
+// flush reports the summaries of the suppressed errors & removes the idle buckets
+func (l *limiter) flush() {
+	l.mu.Lock()
+	now := l.now()
+	var summaries []summary
+	for key, b := range l.buckets {
+		if b.suppressed > 0 {
+			summaries = append(summaries, summary{
+				count:  b.suppressed,
+				last:   b.last,
+				basics: b.basics,
+				extras: b.extras,
+				level:  b.level,
+			})
+			b.suppressed = 0
+			b.last, b.basics, b.extras = nil, nil, nil
+			continue
+		}
+
+		l.refill(b, now)
+		if b.tokens >= float64(l.limits.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, s := range summaries {
+		report(context.Background(), s.err(), s.level)
+	}
+}
+
+// err returns the summary as an error wrapping the last suppressed error, so it's grouped with the suppressed errors.
+// The error is built with its own contexts instead of New, since propagating the contexts would modify the last
+// suppressed error, which is still used by the caller.
+func (s summary) err() error {
+	extras := common.Extras{}
+	maps.Copy(extras, s.extras)
+	extras[SuppressedCount] = s.count
+
+	return &Error{
+		Kind: Kind(Code(s.last)),
+		Err:  s.last,
+		msg:  fmt.Sprintf("%d similar errors suppressed", s.count),
+		ctx:  map[string]any{ctxBasics: s.basics, ctxExtras: extras},
+	}
+}
+
+// fingerprintKey returns the key grouping similar errors
+func fingerprintKey(err error) string {
+	key := strconv.Itoa(Code(err)) + "|" + joinOps(Ops(err)) + "|"
+	stack := innermostStack(err)
+	if len(stack) == 0 {
+		return key + fmt.Sprintf("%T", err)
+	}
+
+	frame, _ := runtime.CallersFrames(stack[:1]).Next()
+	return key + frame.File + ":" + strconv.Itoa(frame.Line)
+}
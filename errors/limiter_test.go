@@ -0,0 +1,151 @@
+package errors
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/common"
+)
+
+func newTestLimiter(limits CaptureLimits) (*limiter, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newLimiter(limits)
+	l.now = func() time.Time { return now }
+	l.random = func() float64 { return 0.5 }
+	return l, &now
+}
+
+func newSameError() error {
+	return New(Op(parentOp), NotFound, parentErrMsg)
+}
+
+func Test_limiter_allow_RateLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	l, now := newTestLimiter(CaptureLimits{Burst: 2, Rate: 1})
+
+	assert.True(l.allow(newSameError(), LevelError))
+	assert.True(l.allow(newSameError(), LevelError))
+	assert.False(l.allow(newSameError(), LevelError))
+
+	// errors of another fingerprint have their own bucket
+	assert.True(l.allow(New(Op(childOp), NotFound, childErrMsg), LevelError))
+
+	*now = now.Add(time.Second)
+	assert.True(l.allow(newSameError(), LevelError))
+	assert.False(l.allow(newSameError(), LevelError))
+}
+
+func Test_limiter_allow_SampleRates(t *testing.T) {
+	assert := assert.New(t)
+
+	l, _ := newTestLimiter(CaptureLimits{SampleRates: map[Kind]float64{NotFound: 0.1, Conflict: 0.9}})
+
+	assert.False(l.allow(New(NotFound), LevelError))
+	assert.True(l.allow(New(Conflict), LevelError))
+	assert.True(l.allow(New(Unexpected), LevelError))
+}
+
+func Test_limiter_flush(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := &Recorder{}
+	RegisterReporter(recorderName, recorder)
+	defer UnregisterReporter(recorderName)
+
+	l, now := newTestLimiter(CaptureLimits{Burst: 1, Rate: 1})
+
+	var last error
+	for i := 0; i < 4; i++ {
+		last = newSameError()
+		l.allow(last, LevelWarning)
+	}
+	l.allow(New(Op(childOp), Conflict, childErrMsg), LevelError)
+
+	l.flush()
+	captured := recorder.Captured()
+	if assert.Len(captured, 1) {
+		assert.Equal(LevelWarning, captured[0].Level)
+		assert.Equal("3 similar errors suppressed: "+parentErrMsg, captured[0].Err.Error())
+		assert.Equal(int(NotFound), Code(captured[0].Err))
+		assert.Equal(3, Extras(captured[0].Err)[SuppressedCount])
+		assert.Equal(fingerprintKey(last), fingerprintKey(captured[0].Err))
+	}
+
+	// the counts are reset after a flush & the idle buckets are removed
+	recorder.Reset()
+	*now = now.Add(time.Second)
+	l.flush()
+	assert.Empty(recorder.Captured())
+	assert.Empty(l.buckets)
+}
+
+func Test_limiter_flush_KeepsSuppressedErrorContexts(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := &Recorder{}
+	RegisterReporter(recorderName, recorder)
+	defer UnregisterReporter(recorderName)
+
+	l, _ := newTestLimiter(CaptureLimits{Burst: 1, Rate: 1})
+	ctx := common.SetBasic(context.Background(), "booking_id", "b-1")
+	ctx = common.SetExtra(ctx, "partner", "p-1")
+	newErr := func() error { return New(Op(parentOp), NotFound, parentErrMsg, ctx) }
+
+	l.allow(newErr(), LevelError)
+	err := newErr()
+	l.allow(err, LevelError)
+
+	// the caller still renders the error while the suppressed errors are flushed
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = Basics(err)
+			_ = Extras(err)
+		}
+	}()
+	l.flush()
+	wg.Wait()
+
+	assert.Equal(common.Basics{"booking_id": "b-1"}, Basics(err))
+	assert.Equal(common.Extras{"partner": "p-1"}, Extras(err))
+	captured := recorder.Captured()
+	if assert.Len(captured, 1) {
+		assert.Equal(common.Basics{"booking_id": "b-1"}, Basics(captured[0].Err))
+		assert.Equal(common.Extras{"partner": "p-1", SuppressedCount: 1}, Extras(captured[0].Err))
+	}
+}
+
+func TestSetCaptureLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := &Recorder{}
+	RegisterReporter(recorderName, recorder)
+	defer UnregisterReporter(recorderName)
+
+	SetCaptureLimits(&CaptureLimits{Burst: 1, SummaryInterval: time.Hour})
+	for i := 0; i < 3; i++ {
+		CaptureError(context.Background(), newSameError())
+	}
+	assert.Len(recorder.Captured(), 1)
+
+	// the suppressed errors are reported when the limits are removed
+	SetCaptureLimits(nil)
+	assert.Eventually(func() bool { return len(recorder.Captured()) == 2 }, time.Second, 10*time.Millisecond)
+
+	CaptureError(context.Background(), newSameError())
+	assert.Len(recorder.Captured(), 3)
+}
+
+func Test_fingerprintKey(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(fingerprintKey(newSameError()), fingerprintKey(newSameError()))
+	assert.NotEqual(fingerprintKey(newSameError()), fingerprintKey(New(Op(parentOp), NotFound, parentErrMsg)))
+	assert.NotEqual(fingerprintKey(New(NotFound, NoStack)), fingerprintKey(New(Conflict, NoStack)))
+}
@@ -0,0 +1,35 @@
+package errors
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogReporterName is the suggested name to register the LogReporter with
+const LogReporterName = "log"
+
+// LogReporter reports errors as structured logs
+type LogReporter struct {
+	// Logger is the logger to write to, slog.Default() is used when it's nil
+	Logger *slog.Logger
+}
+
+// Report logs the error with its code, operations, basics & extras
+func (r LogReporter) Report(ctx context.Context, err error, level Level) {
+	logger := r.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	logLevel := slog.LevelError
+	if level == LevelWarning {
+		logLevel = slog.LevelWarn
+	}
+
+	logger.LogAttrs(ctx, logLevel, err.Error(),
+		slog.Int(SentryErrorCode, Code(err)),
+		slog.Any(SentryOperations, Ops(err)),
+		slog.Any(ctxBasics, Basics(err)),
+		slog.Any(ctxExtras, Extras(err)),
+	)
+}
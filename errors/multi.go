@@ -0,0 +1,174 @@
+package errors
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/wego/pkg/collection"
+	"github.com/wego/pkg/common"
+)
+
+// Multi aggregates multiple errors, e.g. the failures of a batch. It's safe to append errors concurrently.
+type Multi struct {
+	Op   Op
+	mu   sync.RWMutex
+	errs []error
+	ctx  map[string]any
+}
+
+// NewMulti construct a new Multi error for the operation with the given errors, nil errors are skipped
+func NewMulti(op Op, errs ...error) *Multi {
+	m := &Multi{Op: op}
+	return m.Append(errs...)
+}
+
+// Append adds the errors into the Multi error, nil errors are skipped.
+// The basics & extras of the errors are merged into the Multi error, the later error wins on the same key.
+func (m *Multi) Append(errs ...error) *Multi {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		m.errs = append(m.errs, err)
+		m.mergeContexts(err)
+	}
+	return m
+}
+
+// Len returns the number of errors
+func (m *Multi) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.errs)
+}
+
+// Errors returns a copy of the errors
+func (m *Multi) Errors() []error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]error(nil), m.errs...)
+}
+
+// ErrorOrNil returns nil when there is no error, so the Multi error can be returned as an error directly
+func (m *Multi) ErrorOrNil() error {
+	if m == nil || m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// Unwrap returns the errors, so the standard errors.Is & errors.As can traverse all of them
+func (m *Multi) Unwrap() []error {
+	return m.Errors()
+}
+
+// Kind resolves the overall Kind of the errors, see ResolveKind
+func (m *Multi) Kind() Kind {
+	return ResolveKind(m.Errors())
+}
+
+func (m *Multi) Error() string {
+	errs := m.Errors()
+
+	var sb strings.Builder
+	if m.Op != "" {
+		_, _ = sb.WriteString(string(m.Op) + ": ")
+	}
+
+	switch len(errs) {
+	case 0:
+		_, _ = sb.WriteString("no error")
+		return sb.String()
+	case 1:
+		_, _ = sb.WriteString("1 error occurred: ")
+	default:
+		_, _ = sb.WriteString(strconv.Itoa(len(errs)) + " errors occurred: ")
+	}
+
+	for i, err := range errs {
+		if i > 0 {
+			_, _ = sb.WriteString("; ")
+		}
+		_, _ = sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+/*
+ResolveKind resolves the overall Kind of multiple errors with the precedence below:
+
+ 1. 0 when there is no error
+ 2. Unexpected when any of the errors is Unexpected or a server error (5xx), including errors without a Kind
+ 3. the Kind of the errors when all of them are of the same Kind, e.g. all NotFound stays NotFound
+ 4. Retry when any of the errors should be retried
+ 5. BadRequest for the remaining mixed client errors
+*/
+func ResolveKind(errs []error) Kind {
+	if len(errs) == 0 {
+		return 0
+	}
+
+	kinds := make([]Kind, 0, len(errs))
+	for _, err := range errs {
+		kinds = append(kinds, Kind(Code(err)))
+	}
+
+	switch {
+	case collection.Any(kinds, func(k Kind) bool { return k >= Unexpected }):
+		return Unexpected
+	case collection.All(kinds, func(k Kind) bool { return k == kinds[0] }):
+		return kinds[0]
+	case collection.Contains(kinds, Retry):
+		return Retry
+	default:
+		return BadRequest
+	}
+}
+
+// mergeContexts copies the basics & extras of the error into the Multi error, the same as propagateContexts
+func (m *Multi) mergeContexts(err error) {
+	subBasics := Basics(err)
+	subExtras := Extras(err)
+	if subBasics == nil && subExtras == nil {
+		return
+	}
+
+	if m.ctx == nil {
+		m.ctx = map[string]any{}
+	}
+
+	basics, _ := m.ctx[ctxBasics].(common.Basics)
+	if basics == nil {
+		basics = common.Basics{}
+	}
+	collection.Copy(basics, subBasics)
+	m.ctx[ctxBasics] = basics
+
+	extras, _ := m.ctx[ctxExtras].(common.Extras)
+	if extras == nil {
+		extras = common.Extras{}
+	}
+	collection.Copy(extras, subExtras)
+	m.ctx[ctxExtras] = extras
+}
+
+// basics returns a copy of the basics, as they're merged in place when errors are appended
+func (m *Multi) basics() common.Basics {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	basics, _ := m.ctx[ctxBasics].(common.Basics)
+	return maps.Clone(basics)
+}
+
+// extras returns a copy of the extras, as they're merged in place when errors are appended
+func (m *Multi) extras() common.Extras {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	extras, _ := m.ctx[ctxExtras].(common.Extras)
+	return maps.Clone(extras)
+}
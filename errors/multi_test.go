@@ -0,0 +1,212 @@
+package errors
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/common"
+)
+
+func TestNewMulti(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMulti(Op(parentOp), nil, New(Op(childOp), NotFound, childErrMsg), nil)
+	assert.Equal(1, m.Len())
+	assert.Equal("parent op: 1 error occurred: child error message", m.Error())
+
+	m.Append(goErrors.New(grandchildErrMsg))
+	assert.Equal(2, m.Len())
+	assert.Equal("parent op: 2 errors occurred: child error message; grandchild error message", m.Error())
+
+	assert.Nil(NewMulti(Op(parentOp)).ErrorOrNil())
+	assert.Equal(m, m.ErrorOrNil())
+}
+
+func TestMulti_Append_Concurrently(t *testing.T) {
+	m := NewMulti(Op(parentOp))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := common.SetBasic(context.Background(), fmt.Sprint("key", i), i)
+			m.Append(New(Op(childOp), NotFound, ctx))
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, m.Len())
+	assert.Len(t, Basics(m), 10)
+	assert.Equal(t, NotFound, m.Kind())
+}
+
+func TestMulti_ReadContexts_Concurrently(t *testing.T) {
+	m := NewMulti(Op(parentOp))
+	appended := make(chan struct{})
+
+	var wg, reading sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		reading.Add(1)
+		go func() {
+			defer wg.Done()
+			reading.Done()
+			for {
+				select {
+				case <-appended:
+					return
+				default:
+				}
+				for key, value := range Basics(m) {
+					_, _ = key, value
+				}
+				_ = Extras(New(Op(parentOp), m))
+			}
+		}()
+	}
+
+	reading.Wait()
+	for i := 0; i < 100; i++ {
+		ctx := common.SetBasic(context.Background(), fmt.Sprint("key", i), i)
+		ctx = common.SetExtra(ctx, fmt.Sprint("key", i), i)
+		m.Append(New(Op(childOp), NotFound, ctx))
+	}
+	close(appended)
+	wg.Wait()
+
+	assert.Len(t, Basics(m), 100)
+	assert.Len(t, Extras(m), 100)
+}
+
+func TestMulti_Unwrap(t *testing.T) {
+	assert := assert.New(t)
+
+	cause := &testCauseError{code: "23505"}
+	m := NewMulti(Op(parentOp), New(Op(childOp), BadRequest), New(Op(childOp), Conflict, cause))
+	err := New(Op(parentOp), fmt.Errorf("wrapped: %w", m))
+
+	assert.True(goErrors.Is(err, ErrConflict))
+	assert.True(goErrors.Is(err, ErrBadRequest))
+	assert.False(goErrors.Is(err, ErrNotFound))
+
+	var target *testCauseError
+	assert.True(goErrors.As(err, &target))
+	assert.Equal(cause, target)
+}
+
+func TestResolveKind(t *testing.T) {
+	tests := []struct {
+		name string
+		errs []error
+		want Kind
+	}{
+		{
+			name: "no error",
+			want: 0,
+		},
+		{
+			name: "any unexpected",
+			errs: []error{New(NotFound), New(Unexpected), New(Retry)},
+			want: Unexpected,
+		},
+		{
+			name: "any server error",
+			errs: []error{New(NotFound), New(Kind(502))},
+			want: Unexpected,
+		},
+		{
+			name: "any error without kind",
+			errs: []error{New(NotFound), goErrors.New(childErrMsg)},
+			want: Unexpected,
+		},
+		{
+			name: "all not found",
+			errs: []error{New(NotFound), New(Op(childOp), New(NotFound))},
+			want: NotFound,
+		},
+		{
+			name: "any retry",
+			errs: []error{New(NotFound), New(Retry), New(Conflict)},
+			want: Retry,
+		},
+		{
+			name: "mixed client errors",
+			errs: []error{New(NotFound), New(Conflict)},
+			want: BadRequest,
+		},
+		{
+			name: "nested multi",
+			errs: []error{NewMulti(Op(childOp), New(Conflict)), New(Conflict)},
+			want: Conflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ResolveKind(tt.errs))
+		})
+	}
+}
+
+func TestCode_Multi(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMulti(Op(parentOp), New(NotFound), New(NotFound))
+	assert.Equal(int(NotFound), Code(m))
+	assert.Equal(int(NotFound), Code(New(Op(parentOp), m)))
+	assert.Equal(int(Forbidden), Code(New(Op(parentOp), Forbidden, m)))
+	assert.Equal(int(Conflict), Code(goErrors.Join(New(Conflict), New(Conflict))))
+	assert.Equal(int(Unexpected), Code(NewMulti(Op(parentOp))))
+}
+
+func TestMulti_Contexts(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx1 := common.SetBasic(context.Background(), "key1", "value1")
+	ctx1 = common.SetExtra(ctx1, "key2", "value2")
+	ctx2 := common.SetBasic(context.Background(), "key1", "value1-override")
+	ctx2 = common.SetBasic(ctx2, "key3", "value3")
+
+	m := NewMulti(Op(childOp), New(Op(childOp), ctx1), New(Op(childOp), ctx2))
+	assert.Equal(common.Basics{"key1": "value1-override", "key3": "value3"}, m.basics())
+	assert.Equal(common.Extras{"key2": "value2"}, m.extras())
+
+	err := New(Op(parentOp), m, common.SetBasic(context.Background(), "key4", "value4"))
+	assert.Equal(common.Basics{"key1": "value1-override", "key3": "value3", "key4": "value4"}, Basics(err))
+	assert.Equal(common.Extras{"key2": "value2"}, Extras(err))
+}
+
+func Test_enrichScope_Multi(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMulti(Op(childOp), New(Op(grandchildOp), NotFound, grandchildErrMsg), goErrors.New(childErrMsg))
+	err := New(Op(parentOp), parentErrMsg, m)
+
+	scope := sentry.NewScope()
+	enrichScope(context.Background(), scope, err)
+
+	event := sentry.NewEvent()
+	event.SetException(err, -1)
+	event = scope.ApplyToEvent(event, nil, nil)
+
+	assert.Len(event.Exception, 4)
+	assert.Equal("*errors.Error", event.Exception[0].Type)
+	assert.Equal(grandchildErrMsg, event.Exception[0].Value)
+	assert.Equal("errors[0]", event.Exception[0].Mechanism.Source)
+	assert.Equal("*errors.errorString", event.Exception[1].Type)
+	assert.Equal("errors[1]", event.Exception[1].Mechanism.Source)
+
+	group := event.Exception[2]
+	assert.Equal("*errors.Multi", group.Type)
+	assert.True(group.Mechanism.IsExceptionGroup)
+	assert.Equal(group.Mechanism.ExceptionID, *event.Exception[0].Mechanism.ParentID)
+	assert.Equal(group.Mechanism.ExceptionID, *event.Exception[1].Mechanism.ParentID)
+	assert.Equal(err.Error(), event.Exception[3].Value)
+	assert.Equal([]string{"{{default}}", "500"}, event.Fingerprint)
+}
@@ -0,0 +1,230 @@
+// Package problem renders errors as RFC 9457 problem details (application/problem+json)
+package problem
+
+import (
+	"encoding/json"
+	goErrors "errors"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/wego/pkg/errors"
+	"github.com/wego/pkg/http/header"
+	"golang.org/x/text/language"
+)
+
+// ContentType is the media type of a problem details body
+const ContentType = "application/problem+json"
+
+const (
+	defaultType      = "about:blank"
+	validationDetail = "request validation failed"
+	basicsKey        = "basics"
+	extrasKey        = "extras"
+	defaultLang      = "en"
+)
+
+// Config configures how errors are rendered as problem details
+type Config struct {
+	// TypeBaseURI is the base of the `type` member, the kebab-cased status text is appended to it, e.g.
+	// https://developers.wego.com/problems/not-found. The type is `about:blank` when it's empty.
+	TypeBaseURI string
+	// Basics are the keys of the error basics which are safe to expose to the client
+	Basics []string
+	// Extras are the keys of the error extras which are safe to expose to the client
+	Extras []string
+}
+
+// Problem is a problem details object, see https://www.rfc-editor.org/rfc/rfc9457
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	// Extensions are additional members, they are rendered at the top level of the body
+	Extensions map[string]any `json:"-"`
+}
+
+// FieldError is a validation failure of a single field
+type FieldError struct {
+	Field  string `json:"field"`
+	Tag    string `json:"tag"`
+	Param  string `json:"param,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// MarshalJSON marshals the problem with its extensions flattened into the top level members
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	body, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return body, err
+	}
+
+	members := make(map[string]any, len(p.Extensions))
+	maps.Copy(members, p.Extensions)
+	if err = json.Unmarshal(body, &members); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// Status returns the HTTP status of an error. The kinds which are not HTTP status codes are mapped as below:
+//   - Retry -> 503 Service Unavailable
+//   - NotSupported -> 400 Bad Request
+//   - NotImplemented -> 501 Not Implemented
+func Status(err error) int {
+	var validationErrs validator.ValidationErrors
+	if errors.Code(err) == int(errors.Unexpected) && goErrors.As(err, &validationErrs) {
+		return http.StatusBadRequest
+	}
+
+	switch kind := errors.Kind(errors.Code(err)); kind {
+	case errors.Retry:
+		return http.StatusServiceUnavailable
+	case errors.NotSupported:
+		return http.StatusBadRequest
+	case errors.NotImplemented:
+		return http.StatusNotImplemented
+	default:
+		if http.StatusText(int(kind)) == "" {
+			return http.StatusInternalServerError
+		}
+		return int(kind)
+	}
+}
+
+// New builds the problem details of an error. The message of the outermost Error is only exposed as `detail` for
+// client errors, the messages of the errors it wraps, e.g. the database errors, are never exposed.
+func New(err error, instance string, conf Config) *Problem {
+	return NewLocalized(err, instance, defaultLang, conf)
+}
+
+// NewLocalized builds the problem details of an error like New, with the details of the field errors localized in the
+// language, see errors.FieldError.Localize
+func NewLocalized(err error, instance string, lang string, conf Config) *Problem {
+	status := Status(err)
+	p := &Problem{
+		Type:     typeURI(conf.TypeBaseURI, status),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: instance,
+		Errors:   fieldErrors(err, lang),
+	}
+
+	switch {
+	case len(p.Errors) > 0:
+		// the message of validator.ValidationErrors exposes the Go struct names, the details are in the errors member
+		p.Detail = validationDetail
+	case status < http.StatusInternalServerError:
+		p.Detail = detail(err)
+	}
+
+	if basics := allowed(errors.Basics(err), conf.Basics); len(basics) > 0 {
+		p.setExtension(basicsKey, basics)
+	}
+	if extras := allowed(errors.Extras(err), conf.Extras); len(extras) > 0 {
+		p.setExtension(extrasKey, extras)
+	}
+
+	return p
+}
+
+// Handler returns a gin middleware rendering the last error of the context as problem details, when the handlers
+// have not written the response
+func Handler(conf Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		Render(c, c.Errors.Last().Err, conf)
+	}
+}
+
+// Render aborts the request & writes the error as problem details, localized in the preferred language of the
+// Accept-Language header
+func Render(c *gin.Context, err error, conf Config) {
+	p := NewLocalized(err, c.GetHeader(header.RequestID), preferredLang(c.GetHeader(header.AcceptLanguage)), conf)
+	if p.Status == http.StatusNotModified {
+		c.AbortWithStatus(p.Status)
+		return
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Abort()
+	c.Data(p.Status, ContentType, body)
+}
+
+func (p *Problem) setExtension(key string, value any) {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[key] = value
+}
+
+// detail returns the own message of the outermost Error of the chain
+func detail(err error) string {
+	var e *errors.Error
+	if goErrors.As(err, &e) {
+		return e.Message()
+	}
+	return ""
+}
+
+func typeURI(base string, status int) string {
+	if base == "" {
+		return defaultType
+	}
+	slug := strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "-"))
+	return strings.TrimSuffix(base, "/") + "/" + slug
+}
+
+func fieldErrors(err error, lang string) (res []FieldError) {
+	var validationErrs validator.ValidationErrors
+	if !goErrors.As(err, &validationErrs) {
+		return
+	}
+
+	for _, fe := range validationErrs {
+		e := errors.FieldError{FieldError: fe}
+		res = append(res, FieldError{
+			Field:  e.Path(),
+			Tag:    fe.ActualTag(),
+			Param:  fe.Param(),
+			Detail: e.Localize(lang),
+		})
+	}
+	return
+}
+
+// preferredLang returns the base language of the most preferred tag of an Accept-Language header
+func preferredLang(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return defaultLang
+	}
+	base, _ := tags[0].Base()
+	return base.String()
+}
+
+func allowed[M ~map[string]any](values M, keys []string) map[string]any {
+	res := make(map[string]any, len(keys))
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			res[key] = value
+		}
+	}
+	return res
+}
@@ -0,0 +1,222 @@
+package problem_test
+
+import (
+	"context"
+	"encoding/json"
+	goErrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/common"
+	"github.com/wego/pkg/errors"
+	"github.com/wego/pkg/errors/problem"
+	"github.com/wego/pkg/http/header"
+)
+
+type testRequest struct {
+	Name   string `json:"name" binding:"required"`
+	Parent struct {
+		Child string `json:"child" binding:"required"`
+	} `json:"parent"`
+}
+
+func newRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(problem.Handler(problem.Config{
+		TypeBaseURI: "https://example.com/problems/",
+		Basics:      []string{"site_code"},
+		Extras:      []string{"booking_id"},
+	}))
+	r.POST("/", handler)
+	return r
+}
+
+func serve(r *gin.Engine, body string) (*httptest.ResponseRecorder, map[string]any) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(header.RequestID, "req-123")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var res map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	return w, res
+}
+
+func TestHandler_Error(t *testing.T) {
+	assert := assert.New(t)
+
+	r := newRouter(func(c *gin.Context) {
+		ctx := common.SetBasic(context.Background(), "site_code", "SG")
+		ctx = common.SetBasic(ctx, "user_email", "someone@wego.com")
+		ctx = common.SetExtra(ctx, "booking_id", "123")
+		_ = c.Error(errors.New(errors.Op("test"), errors.NotFound, "booking not found", ctx))
+	})
+
+	w, res := serve(r, "")
+	assert.Equal(http.StatusNotFound, w.Code)
+	assert.Equal(problem.ContentType, w.Header().Get(header.ContentType))
+	assert.Equal(map[string]any{
+		"type":     "https://example.com/problems/not-found",
+		"title":    "Not Found",
+		"status":   float64(http.StatusNotFound),
+		"detail":   "booking not found",
+		"instance": "req-123",
+		"basics":   map[string]any{"site_code": "SG"},
+		"extras":   map[string]any{"booking_id": "123"},
+	}, res)
+}
+
+func TestHandler_ValidationErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	r := newRouter(func(c *gin.Context) {
+		var req testRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			_ = c.Error(err)
+		}
+	})
+
+	w, res := serve(r, `{}`)
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Equal("request validation failed", res["detail"])
+	assert.Equal([]any{
+		map[string]any{
+			"field":  "Name",
+			"tag":    "required",
+			"detail": "Name is required",
+		},
+		map[string]any{
+			"field":  "Parent.Child",
+			"tag":    "required",
+			"detail": "Parent.Child is required",
+		},
+	}, res["errors"])
+}
+
+func TestHandler_ValidationErrors_Localized(t *testing.T) {
+	assert := assert.New(t)
+
+	errors.SetLocalizer(func(lang string, msgID string, data interface{}) (string, error) {
+		if lang != "ar" || msgID != "errors.validation.required" {
+			return "", goErrors.New("message not found")
+		}
+		return data.(map[string]any)["Field"].(string) + " مطلوب", nil
+	})
+	defer errors.SetLocalizer(nil)
+
+	r := newRouter(func(c *gin.Context) {
+		var req testRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			_ = c.Error(err)
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	req.Header.Set(header.AcceptLanguage, "ar-AE,ar;q=0.9,en;q=0.8")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var res problem.Problem
+	assert.NoError(json.Unmarshal(w.Body.Bytes(), &res))
+	if assert.Len(res.Errors, 2) {
+		assert.Equal("Name مطلوب", res.Errors[0].Detail)
+	}
+}
+
+func TestHandler_Written(t *testing.T) {
+	assert := assert.New(t)
+
+	r := newRouter(func(c *gin.Context) {
+		_ = c.Error(errors.New(errors.Conflict))
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	w, res := serve(r, "")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal(map[string]any{"ok": true}, res)
+}
+
+func TestHandler_NotModified(t *testing.T) {
+	assert := assert.New(t)
+
+	r := newRouter(func(c *gin.Context) {
+		_ = c.Error(errors.ErrNotModified)
+	})
+
+	w, _ := serve(r, "")
+	assert.Equal(http.StatusNotModified, w.Code)
+	assert.Empty(w.Body.String())
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "non Error", err: goErrors.New("boom"), want: http.StatusInternalServerError},
+		{name: "Error without kind", err: errors.New("boom"), want: http.StatusInternalServerError},
+		{name: "BadRequest", err: errors.New(errors.BadRequest), want: http.StatusBadRequest},
+		{name: "Retry", err: errors.New(errors.Retry), want: http.StatusServiceUnavailable},
+		{name: "NotSupported", err: errors.ErrNotSupported, want: http.StatusBadRequest},
+		{name: "NotImplemented", err: errors.ErrNotImplemented, want: http.StatusNotImplemented},
+		{name: "unknown kind", err: errors.New(errors.Kind(-100)), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, problem.Status(tt.err))
+		})
+	}
+}
+
+func TestNew_HidesServerErrorDetail(t *testing.T) {
+	assert := assert.New(t)
+
+	p := problem.New(errors.New(errors.Unexpected, "pq: connection refused"), "req-123", problem.Config{})
+	assert.Equal("about:blank", p.Type)
+	assert.Equal("Internal Server Error", p.Title)
+	assert.Empty(p.Detail)
+	assert.Empty(p.Extensions)
+}
+
+func TestNew_HidesWrappedErrorDetail(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "Error with message",
+			err:  errors.New(errors.Op("booking.Get"), errors.NotFound, "booking not found"),
+			want: "booking not found",
+		},
+		{
+			name: "Error wrapping a database error",
+			err: errors.New(errors.Op("booking.Create"), errors.Conflict, "booking already exists",
+				goErrors.New(`duplicate key value violates unique constraint "idx_bookings_code"`)),
+			want: "booking already exists",
+		},
+		{
+			name: "Error without message",
+			err:  errors.New(errors.Op("booking.Create"), errors.Conflict, goErrors.New("duplicate key value")),
+		},
+		{
+			name: "non Error",
+			err:  goErrors.New("duplicate key value"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := problem.New(tt.err, "req-123", problem.Config{})
+			assert.Equal(t, tt.want, p.Detail)
+		})
+	}
+}
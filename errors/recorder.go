@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// Captured is an error captured by a Recorder
+type Captured struct {
+	Err   error
+	Level Level
+}
+
+// Recorder records the captured errors in memory, use it in tests to assert on what would have been reported:
+//
+//	recorder := &errors.Recorder{}
+//	errors.RegisterReporter("recorder", recorder)
+//	defer errors.UnregisterReporter("recorder")
+type Recorder struct {
+	mu       sync.Mutex
+	captured []Captured
+}
+
+// Report records the error
+func (r *Recorder) Report(_ context.Context, err error, level Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.captured = append(r.captured, Captured{Err: err, Level: level})
+}
+
+// Captured returns a copy of the recorded errors
+func (r *Recorder) Captured() []Captured {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Captured(nil), r.captured...)
+}
+
+// Reset removes all the recorded errors
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.captured = nil
+}
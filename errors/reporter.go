@@ -0,0 +1,120 @@
+package errors
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/wego/pkg/env"
+)
+
+// Level the severity of a captured error
+type Level string
+
+// levels of captured errors, the values are the same as Sentry levels
+const (
+	LevelError   Level = "error"
+	LevelWarning Level = "warning"
+)
+
+// Reporter reports captured errors to an error tracking backend
+type Reporter interface {
+	Report(ctx context.Context, err error, level Level)
+}
+
+// ReporterFunc is an adapter to use a function as a Reporter
+type ReporterFunc func(ctx context.Context, err error, level Level)
+
+// Report calls f(ctx, err, level)
+func (f ReporterFunc) Report(ctx context.Context, err error, level Level) {
+	f(ctx, err, level)
+}
+
+// registeredReporter is a reporter with the environments it's enabled in
+type registeredReporter struct {
+	name         string
+	reporter     Reporter
+	environments []string
+}
+
+var (
+	reportersMu sync.RWMutex
+	reporters   = []registeredReporter{
+		{
+			name:         SentryReporterName,
+			reporter:     SentryReporter{},
+			environments: []string{env.Production, env.Staging},
+		},
+	}
+)
+
+// CaptureError captures an error to the registered reporters & set level as error
+func CaptureError(ctx context.Context, err error) {
+	capture(ctx, err, LevelError)
+}
+
+// CaptureWarning captures an error to the registered reporters & set level as warning
+func CaptureWarning(ctx context.Context, err error) {
+	capture(ctx, err, LevelWarning)
+}
+
+/*
+RegisterReporter registers a reporter with a unique name, an existing reporter with the same name is replaced.
+The reporter is only enabled in the given environments, or in all environments when none is given.
+
+The Sentry reporter is registered by default as SentryReporterName, enabled in production & staging. Register it
+again to change its environments, e.g. in local & CI runs:
+
+	errors.RegisterReporter(errors.SentryReporterName, errors.SentryReporter{}, "development", "test")
+*/
+func RegisterReporter(name string, reporter Reporter, environments ...string) {
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+
+	r := registeredReporter{name: name, reporter: reporter, environments: environments}
+	if i := slices.IndexFunc(reporters, func(r registeredReporter) bool { return r.name == name }); i >= 0 {
+		reporters[i] = r
+		return
+	}
+	reporters = append(reporters, r)
+}
+
+// UnregisterReporter removes the reporter registered with the name
+func UnregisterReporter(name string) {
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+
+	reporters = slices.DeleteFunc(reporters, func(r registeredReporter) bool { return r.name == name })
+}
+
+func capture(ctx context.Context, err error, level Level) {
+	if err == nil {
+		return
+	}
+
+	if l := captureLimiter.Load(); l != nil && !l.allow(err, level) {
+		return
+	}
+	report(ctx, err, level)
+}
+
+// report reports the error to the reporters enabled in the current environment
+func report(ctx context.Context, err error, level Level) {
+	for _, r := range enabledReporters() {
+		r.Report(ctx, err, level)
+	}
+}
+
+// enabledReporters returns the reporters enabled in the current environment
+func enabledReporters() (res []Reporter) {
+	reportersMu.RLock()
+	defer reportersMu.RUnlock()
+
+	current := env.Env()
+	for _, r := range reporters {
+		if len(r.environments) == 0 || slices.Contains(r.environments, current) {
+			res = append(res, r.reporter)
+		}
+	}
+	return
+}
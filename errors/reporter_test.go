@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	goErrors "errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-go/statsd"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/common"
+	"github.com/wego/pkg/env"
+)
+
+const recorderName = "recorder"
+
+func setEnv(t *testing.T, environment string) {
+	previous := env.Env()
+	viper.Set("env", environment)
+	t.Cleanup(func() { viper.Set("env", previous) })
+}
+
+func TestCapture_Recorder(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := &Recorder{}
+	RegisterReporter(recorderName, recorder)
+	defer UnregisterReporter(recorderName)
+
+	err := New(Op(parentOp), NotFound, parentErrMsg)
+	CaptureError(context.Background(), err)
+	CaptureWarning(context.Background(), goErrors.New(childErrMsg))
+	CaptureError(context.Background(), nil)
+
+	captured := recorder.Captured()
+	if assert.Len(captured, 2) {
+		assert.Equal(Captured{Err: err, Level: LevelError}, captured[0])
+		assert.Equal(LevelWarning, captured[1].Level)
+	}
+
+	recorder.Reset()
+	assert.Empty(recorder.Captured())
+}
+
+func TestRegisterReporter_Environments(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := &Recorder{}
+	RegisterReporter(recorderName, recorder, env.Production)
+	defer UnregisterReporter(recorderName)
+
+	setEnv(t, "development")
+	CaptureError(context.Background(), New(parentErrMsg))
+	assert.Empty(recorder.Captured())
+
+	setEnv(t, env.Production)
+	CaptureError(context.Background(), New(parentErrMsg))
+	assert.Len(recorder.Captured(), 1)
+
+	// registering with the same name replaces the reporter
+	RegisterReporter(recorderName, recorder, env.Staging)
+	CaptureError(context.Background(), New(parentErrMsg))
+	assert.Len(recorder.Captured(), 1)
+
+	UnregisterReporter(recorderName)
+	setEnv(t, env.Staging)
+	CaptureError(context.Background(), New(parentErrMsg))
+	assert.Len(recorder.Captured(), 1)
+}
+
+func TestLogReporter_Report(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	reporter := LogReporter{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}
+
+	ctx := common.SetBasic(context.Background(), "key1", "value1")
+	err := New(Op(parentOp), New(Op(childOp), Conflict, childErrMsg, ctx))
+	reporter.Report(context.Background(), err, LevelWarning)
+
+	var record map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal("WARN", record["level"])
+	assert.Equal(childErrMsg, record["msg"])
+	assert.Equal(float64(Conflict), record[SentryErrorCode])
+	assert.Equal([]any{parentOp, childOp}, record[SentryOperations])
+	assert.Equal(map[string]any{"key1": "value1"}, record["basics"])
+}
+
+func TestDatadogReporter_Report(t *testing.T) {
+	assert := assert.New(t)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.NoError(err) {
+		return
+	}
+	defer conn.Close()
+
+	client, err := statsd.New(conn.LocalAddr().String())
+	if !assert.NoError(err) {
+		return
+	}
+	defer client.Close()
+
+	// nothing is reported without a statsD client
+	DatadogReporter{}.Report(context.Background(), New(parentErrMsg), LevelError)
+
+	ctx := common.SetStatsD(context.Background(), client)
+	DatadogReporter{}.Report(ctx, New(Op(parentOp), NotFound, parentErrMsg), LevelError)
+	assert.NoError(client.Flush())
+
+	var received strings.Builder
+	buf := make([]byte, 4096)
+	for !strings.Contains(received.String(), "_e{") || !strings.Contains(received.String(), defaultDatadogMetric) {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+		_, _ = received.Write(buf[:n])
+	}
+
+	assert.Contains(received.String(), defaultDatadogMetric+":1|c|#error_code:404,level:error,operation:parent op")
+	assert.Contains(received.String(), "[404] parent op|"+parentErrMsg)
+	assert.Contains(received.String(), "|t:error")
+}
@@ -3,32 +3,27 @@ package errors
 import (
 	"context"
 	"encoding/json"
+	goErrors "errors"
 	"fmt"
+	"runtime"
+	"slices"
 
 	"maps"
 
 	"github.com/getsentry/sentry-go"
-	"github.com/wego/pkg/env"
 )
 
-// CaptureError captures an error to sentry & set level as error
-func CaptureError(ctx context.Context, err error) {
-	capture(ctx, err, sentry.LevelError)
-}
-
-// CaptureWarning captures an error to sentry & set level as warning
-func CaptureWarning(ctx context.Context, err error) {
-	capture(ctx, err, sentry.LevelWarning)
-}
+// SentryReporterName is the name of the Sentry reporter which is registered by default
+const SentryReporterName = "sentry"
 
-func capture(ctx context.Context, err error, level sentry.Level) {
-	if !env.IsProduction() && !env.IsStaging() {
-		return
-	}
+// SentryReporter reports errors to Sentry through the hub of the context, or a clone of the current hub
+type SentryReporter struct{}
 
+// Report captures the error to Sentry, enriched with the basics, extras & stack trace of the error
+func (SentryReporter) Report(ctx context.Context, err error, level Level) {
 	hub := getHub(ctx)
 	hub.WithScope(func(scope *sentry.Scope) {
-		scope.SetLevel(level)
+		scope.SetLevel(sentry.Level(level))
 		enrichScope(ctx, scope, err)
 		hub.CaptureException(err)
 	})
@@ -46,11 +41,11 @@ func enrichScope(ctx context.Context, scope *sentry.Scope, err error) {
 	// Fingerprinting is handed over to the SDK ({{default}} is the default fingerprint), with the additional error code field to add a dimension of uniqueness
 	fingerprint := []string{"{{default}}", errorCode}
 
-	// If the error is an Error type, we can enrich the scope with the basics and extras
-	e, ok := err.(*Error)
-	if ok {
-		// For each basic key-value pair, set it as a tag
-		for key, value := range e.basics() {
+	// If the error holds basics and extras, like Error & Multi, we can enrich the scope with them
+	var h contextHolder
+	if goErrors.As(err, &h) {
+		// For each basic key-value pair, including the FieldTag fields, set it as a tag
+		for key, value := range Basics(err) {
 			if tag, err := json.Marshal(value); err == nil {
 				tagsToSet[key] = string(tag)
 			}
@@ -58,16 +53,15 @@ func enrichScope(ctx context.Context, scope *sentry.Scope, err error) {
 
 		// For each operation, set it as an extra
 		// Note: extra is not searchable in Sentry
-		ops := ops(e)
-		extrasToSet[SentryOperations] = ops
+		extrasToSet[SentryOperations] = Ops(err)
 
-		// Merge e.extras() into extrasToSet
+		// Merge the extras, including the FieldExtra & redacted FieldRedacted fields, into extrasToSet
 		// Note: maps.Copy overwrites existing keys in the destination map.
-		maps.Copy(extrasToSet, e.extras())
+		maps.Copy(extrasToSet, Extras(err))
 	}
 
 	// Get the request ID from the context
-	reqID, ok := ctx.Value(SentryRequestID).(string)
+	reqID, ok := RequestID(ctx)
 	if ok {
 		tagsToSet[SentryRequestID] = reqID
 	}
@@ -76,6 +70,93 @@ func enrichScope(ctx context.Context, scope *sentry.Scope, err error) {
 	scope.SetTags(tagsToSet)
 	scope.SetExtras(extrasToSet)
 	scope.SetFingerprint(fingerprint)
+
+	// Point the stack trace at where the innermost Error was constructed instead of where it was captured
+	if stacktrace := newStacktrace(err); stacktrace != nil {
+		scope.AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			if n := len(event.Exception); n > 0 {
+				// the most recent exception is the last one
+				event.Exception[n-1].Stacktrace = stacktrace
+			}
+			return event
+		})
+	}
+
+	// Render each error of a Multi error as an exception linked to it
+	var m *Multi
+	if goErrors.As(err, &m) {
+		scope.AddEventProcessor(func(event *sentry.Event, _ *sentry.EventHint) *sentry.Event {
+			linkExceptions(event, m)
+			return event
+		})
+	}
+}
+
+// linkExceptions adds the errors of the Multi error into the event as exceptions, whose parent is the exception of the
+// Multi error
+func linkExceptions(event *sentry.Event, m *Multi) {
+	parent := slices.IndexFunc(event.Exception, func(exception sentry.Exception) bool {
+		return exception.Type == fmt.Sprintf("%T", m) && exception.Value == m.Error()
+	})
+	if parent < 0 {
+		return
+	}
+
+	nextID := 0
+	for _, exception := range event.Exception {
+		if exception.Mechanism != nil && exception.Mechanism.ExceptionID >= nextID {
+			nextID = exception.Mechanism.ExceptionID + 1
+		}
+	}
+
+	mechanism := event.Exception[parent].Mechanism
+	if mechanism == nil {
+		mechanism = &sentry.Mechanism{Type: "generic", ExceptionID: nextID}
+		event.Exception[parent].Mechanism = mechanism
+		nextID++
+	}
+	mechanism.IsExceptionGroup = true
+
+	errs := m.Errors()
+	children := make([]sentry.Exception, 0, len(errs))
+	for i, err := range errs {
+		children = append(children, sentry.Exception{
+			Type:       fmt.Sprintf("%T", err),
+			Value:      err.Error(),
+			Stacktrace: newStacktrace(err),
+			Mechanism: &sentry.Mechanism{
+				Type:        "chained",
+				Source:      fmt.Sprintf("errors[%d]", i),
+				ExceptionID: nextID + i,
+				ParentID:    sentry.Pointer(mechanism.ExceptionID),
+			},
+		})
+	}
+
+	// keep the outermost error as the last exception, which is the primary one of the event
+	event.Exception = append(children, event.Exception...)
+}
+
+// newStacktrace builds a Sentry stacktrace from the innermost Error in the chain that recorded one
+func newStacktrace(err error) *sentry.Stacktrace {
+	stack := innermostStack(err)
+	if len(stack) == 0 {
+		return nil
+	}
+
+	var frames []sentry.Frame
+	runtimeFrames := runtime.CallersFrames(stack)
+	for {
+		frame, more := runtimeFrames.Next()
+		frames = append(frames, sentry.NewFrame(frame))
+		if !more {
+			break
+		}
+	}
+
+	// Sentry expects the frames ordered from the oldest to the most recent call
+	slices.Reverse(frames)
+	return &sentry.Stacktrace{Frames: frames}
 }
 
 func getHub(ctx context.Context) (hub *sentry.Hub) {
@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_enrichScope_Stacktrace(t *testing.T) {
+	assert := assert.New(t)
+
+	childErr := New(Op(childOp), NotFound, childErrMsg)
+	err := New(Op(parentOp), parentErrMsg, childErr)
+
+	scope := sentry.NewScope()
+	enrichScope(context.Background(), scope, err)
+
+	event := &sentry.Event{Exception: []sentry.Exception{{Value: err.Error()}}}
+	event = scope.ApplyToEvent(event, nil, nil)
+
+	stacktrace := event.Exception[0].Stacktrace
+	if assert.NotNil(stacktrace) && assert.NotEmpty(stacktrace.Frames) {
+		// the most recent frame is the last one, it should be where the child error was constructed
+		frame := stacktrace.Frames[len(stacktrace.Frames)-1]
+		assert.Equal("Test_enrichScope_Stacktrace", frame.Function)
+		childFrame, _ := runtime.CallersFrames(childErr.StackTrace()).Next()
+		assert.Equal(childFrame.Line, frame.Lineno)
+	}
+	assert.Equal([]string{"{{default}}", "404"}, event.Fingerprint)
+}
+
+func Test_enrichScope_NoStack(t *testing.T) {
+	assert := assert.New(t)
+
+	err := New(Op(parentOp), parentErrMsg, NoStack)
+
+	scope := sentry.NewScope()
+	enrichScope(context.Background(), scope, err)
+
+	event := &sentry.Event{Exception: []sentry.Exception{{Value: err.Error()}}}
+	event = scope.ApplyToEvent(event, nil, nil)
+	assert.Nil(event.Exception[0].Stacktrace)
+}
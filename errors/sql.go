@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"context"
+	goErrors "errors"
+	"reflect"
+
+	"github.com/wego/pkg/common"
+)
+
+// extra keys of the details of SQL errors
+const (
+	SQLState      = "sql_state"
+	SQLConstraint = "sql_constraint"
+	SQLTable      = "sql_table"
+	SQLColumn     = "sql_column"
+)
+
+// sqlStateError is a database error with a SQLSTATE code, like the PgError of both github.com/jackc/pgconn &
+// github.com/jackc/pgx/v5/pgconn
+type sqlStateError interface {
+	error
+	SQLState() string
+}
+
+// sqlStateKinds are the kinds of the SQLSTATE codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+var sqlStateKinds = map[string]Kind{
+	"23502": Unprocessable, // not_null_violation
+	"23503": Unprocessable, // foreign_key_violation
+	"23505": Conflict,      // unique_violation
+	"23514": Unprocessable, // check_constraint_violation
+	"23P01": Conflict,      // exclusion_violation
+	"40001": Retry,         // serialization_failure
+	"40P01": Retry,         // deadlock_detected
+	"53300": Retry,         // too_many_connections
+	"55P03": Retry,         // lock_not_available
+	"57014": Retry,         // query_canceled
+	"57P01": Retry,         // admin_shutdown
+	"57P02": Retry,         // crash_shutdown
+	"57P03": Retry,         // cannot_connect_now
+}
+
+// sqlStateClassKinds are the kinds of the SQLSTATE classes, which are the first 2 characters of the codes
+var sqlStateClassKinds = map[string]Kind{
+	"08": Retry,         // connection_exception
+	"22": BadRequest,    // data_exception
+	"23": Unprocessable, // integrity_constraint_violation
+	"40": Retry,         // transaction_rollback
+	"53": Retry,         // insufficient_resources
+}
+
+/*
+ClassifySQLState returns the Kind of a SQLSTATE code, by the code then by its class:
+  - integrity constraint violations -> Unprocessable, except unique & exclusion violations -> Conflict
+  - data exceptions, e.g. invalid text representation -> BadRequest
+  - transaction rollbacks, connection exceptions, insufficient resources, lock not available, query canceled &
+    shutdowns -> Retry
+  - the others -> Unexpected
+*/
+func ClassifySQLState(code string) Kind {
+	if kind, ok := sqlStateKinds[code]; ok {
+		return kind
+	}
+	if len(code) == 5 {
+		if kind, ok := sqlStateClassKinds[code[:2]]; ok {
+			return kind
+		}
+	}
+	return Unexpected
+}
+
+// WrapSQLError wraps a database error with a SQLSTATE code into our error, with the Kind classified by
+// ClassifySQLState & the SQLSTATE code, constraint, table & column as extras. Other errors are wrapped as Unexpected.
+func WrapSQLError(op Op, err error) *Error {
+	var sqlErr sqlStateError
+	if !goErrors.As(err, &sqlErr) {
+		return newError(1, op, err)
+	}
+	return newError(1, op, ClassifySQLState(sqlErr.SQLState()), err, sqlContext(sqlErr))
+}
+
+// sqlContext returns a context with the details of the SQL error as extras
+func sqlContext(err sqlStateError) context.Context {
+	extras := common.Extras{SQLState: err.SQLState()}
+
+	// the details are fields of the PgError structs instead of methods
+	if v := reflect.Indirect(reflect.ValueOf(err)); v.Kind() == reflect.Struct {
+		for key, name := range map[string]string{
+			SQLConstraint: "ConstraintName",
+			SQLTable:      "TableName",
+			SQLColumn:     "ColumnName",
+		} {
+			if f := v.FieldByName(name); f.Kind() == reflect.String && f.String() != "" {
+				extras[key] = f.String()
+			}
+		}
+	}
+	return common.SetExtras(context.Background(), extras)
+}
@@ -0,0 +1,96 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/common"
+	"gorm.io/gorm"
+)
+
+// testPgError has the same shape as the PgError of pgconn
+type testPgError struct {
+	Code           string
+	Message        string
+	TableName      string
+	ColumnName     string
+	ConstraintName string
+}
+
+func (e *testPgError) Error() string {
+	return e.Message + " (SQLSTATE " + e.Code + ")"
+}
+
+func (e *testPgError) SQLState() string {
+	return e.Code
+}
+
+func TestClassifySQLState(t *testing.T) {
+	tests := []struct {
+		code string
+		want Kind
+	}{
+		{code: "23503", want: Unprocessable},
+		{code: "23514", want: Unprocessable},
+		{code: "23502", want: Unprocessable},
+		{code: "23000", want: Unprocessable},
+		{code: "23505", want: Conflict},
+		{code: "23P01", want: Conflict},
+		{code: "22P02", want: BadRequest},
+		{code: "40001", want: Retry},
+		{code: "40P01", want: Retry},
+		{code: "40002", want: Retry},
+		{code: "57014", want: Retry},
+		{code: "53300", want: Retry},
+		{code: "53200", want: Retry},
+		{code: "55P03", want: Retry},
+		{code: "08006", want: Retry},
+		{code: "42P01", want: Unexpected},
+		{code: "", want: Unexpected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifySQLState(tt.code))
+		})
+	}
+}
+
+func TestWrapGORMError_SQLState(t *testing.T) {
+	assert := assert.New(t)
+
+	pgErr := &testPgError{
+		Code:           "23503",
+		Message:        "insert or update on table violates foreign key constraint",
+		TableName:      "bookings",
+		ConstraintName: "fk_bookings_user_id",
+	}
+	err := WrapGORMError(Op(parentOp), fmt.Errorf("create booking: %w", pgErr))
+
+	assert.Equal(Unprocessable, err.Kind)
+	assert.Equal(common.Extras{
+		SQLState:      "23503",
+		SQLTable:      "bookings",
+		SQLConstraint: "fk_bookings_user_id",
+	}, Extras(err))
+
+	err = WrapGORMError(Op(parentOp), &testPgError{Code: "40001", Message: "could not serialize access"})
+	assert.Equal(int(Retry), Code(err))
+	assert.Equal(common.Extras{SQLState: "40001"}, Extras(err))
+
+	assert.Equal(Conflict, WrapGORMError(Op(parentOp), gorm.ErrDuplicatedKey).Kind)
+	assert.Equal(Kind(0), WrapGORMError(Op(parentOp), gorm.ErrInvalidData).Kind)
+}
+
+func TestWrapSQLError(t *testing.T) {
+	assert := assert.New(t)
+
+	err := WrapSQLError(Op(parentOp), &testPgError{Code: "57014", ColumnName: "status"})
+	assert.Equal(Retry, err.Kind)
+	assert.Equal(common.Extras{SQLState: "57014", SQLColumn: "status"}, Extras(err))
+
+	err = WrapSQLError(Op(parentOp), gorm.ErrInvalidData)
+	assert.Equal(int(Unexpected), Code(err))
+	assert.Nil(Extras(err))
+}
@@ -0,0 +1,51 @@
+package errors
+
+import (
+	goErrors "errors"
+	"runtime"
+)
+
+const maxStackDepth = 32
+
+// StackMode controls whether New records the stack trace of its caller
+type StackMode int
+
+const (
+	// NoStack skips recording the stack trace, use it on hot paths where the cost of runtime.Callers matters
+	NoStack StackMode = iota + 1
+)
+
+// StackTrace returns the program counters recorded when the error was constructed.
+// The method name follows the convention of github.com/pkg/errors, so Sentry can extract it by reflection.
+func (e *Error) StackTrace() []uintptr {
+	if e == nil {
+		return nil
+	}
+	return e.stack
+}
+
+// callers returns the program counters of the goroutine's stack, skipping the given number of frames above the caller
+func callers(skip int) []uintptr {
+	var pcs [maxStackDepth]uintptr
+	// skip runtime.Callers & callers itself
+	n := runtime.Callers(skip+2, pcs[:])
+	if n == 0 {
+		return nil
+	}
+
+	stack := make([]uintptr, n)
+	copy(stack, pcs[:n])
+	return stack
+}
+
+// innermostStack returns the stack trace of the innermost Error in the chain that has one
+func innermostStack(err error) (stack []uintptr) {
+	var e *Error
+	for goErrors.As(err, &e) {
+		if len(e.stack) > 0 {
+			stack = e.stack
+		}
+		err = e.Err
+	}
+	return
+}
@@ -1,12 +1,47 @@
 package errors
 
 import (
+	"embed"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
+	"sync/atomic"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
 )
 
+const (
+	validationMsgPrefix    = "errors.validation."
+	defaultValidationMsgID = validationMsgPrefix + "default"
+	defaultLocale          = "en"
+)
+
+// catalog is the bundled English messages of the validation errors, copy it into the locale directory of the service
+// to translate them
+//
+//go:embed locales/en.json
+var catalog embed.FS
+
+var defaultLocalizer = newDefaultLocalizer()
+
+// LocalizeFunc localizes a message & binds the data into the message template, e.g. localization.Localize
+type LocalizeFunc func(lang string, msgID string, data interface{}) (string, error)
+
+var localize atomic.Pointer[LocalizeFunc]
+
+// SetLocalizer sets the function localizing FieldError messages, usually localization.Localize after the locales are
+// registered. The bundled English catalog is used for the messages it cannot localize.
+func SetLocalizer(fn LocalizeFunc) {
+	if fn == nil {
+		localize.Store(nil)
+		return
+	}
+	localize.Store(&fn)
+}
+
 // FieldError is wrapper of validator.FieldError
 type FieldError struct {
 	validator.FieldError
@@ -14,20 +49,8 @@ type FieldError struct {
 
 func (e FieldError) String() string {
 	var sb strings.Builder
-	var field string
 
-	// try to get full path of field if it's a nested field
-	namespace := e.Namespace()
-	if namespace != "" {
-		// namespace has format RootStruct.parent_field.child_field
-		// we don't want to display the RootStruct because it doesn't make sense to the client
-		field = namespace[strings.Index(namespace, ".")+1:]
-	}
-	if field == "" {
-		field = e.Field()
-	}
-
-	_, _ = sb.WriteString("validation failed on field '" + field + "'")
+	_, _ = sb.WriteString("validation failed on field '" + e.Path() + "'")
 	_, _ = sb.WriteString(", condition: " + e.ActualTag())
 
 	if e.Param() != "" {
@@ -40,3 +63,75 @@ func (e FieldError) String() string {
 
 	return sb.String()
 }
+
+// Path returns the full path of the field without the root struct, e.g. parent_field.child_field. The path uses the
+// JSON names of the fields when JSONTagName is registered as the tag name function of the validator.
+func (e FieldError) Path() string {
+	// namespace has format RootStruct.parent_field.child_field
+	// we don't want to display the RootStruct because it doesn't make sense to the client
+	if _, path, found := strings.Cut(e.Namespace(), "."); found && path != "" {
+		return path
+	}
+	return e.Field()
+}
+
+// MessageID returns the ID of the message of the validation tag in the catalog
+func (e FieldError) MessageID() string {
+	return validationMsgPrefix + e.ActualTag()
+}
+
+// Localize returns the client-facing message of the error in the language, falling back to the bundled English
+// catalog, then to a generic message when the tag has no message
+func (e FieldError) Localize(lang string) string {
+	data := map[string]any{
+		"Field": e.Path(),
+		"Tag":   e.ActualTag(),
+		"Param": e.Param(),
+		"Value": e.Value(),
+	}
+
+	if fn := localize.Load(); fn != nil {
+		if msg, err := (*fn)(lang, e.MessageID(), data); err == nil {
+			return msg
+		}
+	}
+
+	for _, id := range []string{e.MessageID(), defaultValidationMsgID} {
+		msg, err := defaultLocalizer.Localize(&i18n.LocalizeConfig{MessageID: id, TemplateData: data})
+		if err == nil {
+			return msg
+		}
+	}
+	return e.String()
+}
+
+// JSONTagName returns the JSON name of a struct field, register it as the tag name function of the validator so the
+// paths of FieldError use the JSON names instead of the Go names:
+//
+//	v.RegisterTagNameFunc(errors.JSONTagName)
+func JSONTagName(fld reflect.StructField) string {
+	name, _, _ := strings.Cut(fld.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return fld.Name
+	default:
+		return name
+	}
+}
+
+func newDefaultLocalizer() *i18n.Localizer {
+	bundle := i18n.NewBundle(language.MustParse(defaultLocale))
+	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
+
+	data, err := catalog.ReadFile("locales/" + defaultLocale + ".json")
+	if err == nil {
+		_, err = bundle.ParseMessageFileBytes(data, defaultLocale+".json")
+	}
+	if err != nil {
+		panic(fmt.Sprintf("errors: invalid bundled catalog: %v", err))
+	}
+
+	return i18n.NewLocalizer(bundle, defaultLocale)
+}
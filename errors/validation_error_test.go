@@ -1,6 +1,7 @@
 package errors
 
 import (
+	goErrors "errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -44,3 +45,72 @@ func Test_FieldError_String_Ok(t *testing.T) {
 	assert.Contains(errStrings[0], "validation failed on field 'top_level', condition: required")
 	assert.Contains(errStrings[1], "validation failed on field 'parent_field.child_field', condition: required")
 }
+
+type customTagStruct struct {
+	Reference string  `json:"reference" validate:"alphanum_with_underscore_or_dash"`
+	Color     *string `json:"color" validate:"one_of_or_blank=red blue"`
+	Code      string  `json:"code" validate:"unknown_tag"`
+}
+
+func newTestValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(JSONTagName)
+	for _, tag := range []string{"alphanum_with_underscore_or_dash", "one_of_or_blank", "unknown_tag"} {
+		_ = v.RegisterValidation(tag, func(validator.FieldLevel) bool { return false })
+	}
+	return v
+}
+
+func Test_FieldError_Localize(t *testing.T) {
+	assert := assert.New(t)
+
+	color := "green"
+	err := newTestValidator().Struct(customTagStruct{Reference: "a b", Color: &color})
+	validationErr, ok := err.(validator.ValidationErrors)
+	if !assert.True(ok) || !assert.Len(validationErr, 3) {
+		return
+	}
+
+	assert.Equal("reference must contain letters, numbers, underscores and dashes only",
+		FieldError{FieldError: validationErr[0]}.Localize("en"))
+	assert.Equal("color must be blank or one of [red blue]", FieldError{FieldError: validationErr[1]}.Localize("ar"))
+	assert.Equal("code is invalid", FieldError{FieldError: validationErr[2]}.Localize("en"))
+
+	SetLocalizer(func(lang string, msgID string, data interface{}) (string, error) {
+		if lang == "ar" && msgID == "errors.validation.one_of_or_blank" {
+			return "قيمة غير صالحة", nil
+		}
+		return "", goErrors.New("message not found")
+	})
+	defer SetLocalizer(nil)
+
+	assert.Equal("قيمة غير صالحة", FieldError{FieldError: validationErr[1]}.Localize("ar"))
+	assert.Equal("code is invalid", FieldError{FieldError: validationErr[2]}.Localize("ar"))
+}
+
+func Test_FieldError_Path(t *testing.T) {
+	assert := assert.New(t)
+
+	err := newTestValidator().Struct(RootStruct{})
+	validationErr, ok := err.(validator.ValidationErrors)
+	if !assert.True(ok) || !assert.Len(validationErr, 2) {
+		return
+	}
+
+	assert.Equal("top_level", FieldError{FieldError: validationErr[0]}.Path())
+	assert.Equal("parent_field.child_field", FieldError{FieldError: validationErr[1]}.Path())
+	assert.Equal("parent_field.child_field is required", FieldError{FieldError: validationErr[1]}.Localize("en"))
+}
+
+func TestJSONTagName(t *testing.T) {
+	type tagged struct {
+		Named    string `json:"named,omitempty"`
+		Skipped  string `json:"-"`
+		Untagged string
+	}
+
+	typ := reflect.TypeOf(tagged{})
+	assert.Equal(t, "named", JSONTagName(typ.Field(0)))
+	assert.Equal(t, "", JSONTagName(typ.Field(1)))
+	assert.Equal(t, "Untagged", JSONTagName(typ.Field(2)))
+}
@@ -1,23 +1,41 @@
 package auth
 
 import (
-	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/casbin/casbin/v2/model"
 	"github.com/casbin/casbin/v2/persist"
+	"github.com/wego/pkg/audit"
+	"github.com/wego/pkg/errors"
 	"gorm.io/gorm"
 )
 
 const (
 	permissionTag = "p"
 	groupTag      = "g"
+	// adapterActor is the requester of the changes made through the adapter, e.g. by the Casbin enforcer
+	adapterActor = "casbin-adapter"
 )
 
-// adapter is a customized read-only gorm adapter for `Casbin`.
-// It can load policy from db
+// ruleLengths are the number of fields of the rules of each policy type:
+// p: role, resource, method & g: user, role
+var ruleLengths = map[string]int{
+	permissionTag: 3,
+	groupTag:      2,
+}
+
+// adapter is a customized gorm adapter for `Casbin`.
+// It loads & saves the policy from & to the auth_* tables, each change is made in a transaction
 type adapter struct {
 	db *gorm.DB
 }
 
+var (
+	_ persist.BatchAdapter     = (*adapter)(nil)
+	_ persist.UpdatableAdapter = (*adapter)(nil)
+)
+
 // newAdapter is the constructor for adapter.
 func newAdapter(db *gorm.DB) *adapter {
 	if db == nil {
@@ -40,16 +58,46 @@ func newAdapter(db *gorm.DB) *adapter {
 	return &adapter{db: db}
 }
 
-func (a *adapter) UpdatePolicy(string, string, []string, []string) error {
-	return errors.New("not implemented")
+// UpdatePolicy updates a policy rule in the storage.
+func (a *adapter) UpdatePolicy(_ string, ptype string, oldRule, newRule []string) error {
+	return a.UpdatePolicies("", ptype, [][]string{oldRule}, [][]string{newRule})
 }
 
-func (a *adapter) UpdatePolicies(string, string, [][]string, [][]string) error {
-	return errors.New("not implemented")
+// UpdatePolicies updates policy rules in the storage, the old rules are replaced by the new rules of the same index.
+// The rules are updated in place, an old rule not in the storage is a NotFound error & a new rule already in the
+// storage is a Conflict error.
+func (a *adapter) UpdatePolicies(_ string, ptype string, oldRules, newRules [][]string) error {
+	if len(oldRules) != len(newRules) {
+		return errors.New(errors.BadRequest, "the number of the old rules & the new rules are different")
+	}
+
+	req := adapterRequest("UpdatePolicies")
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		for i := range oldRules {
+			if err := updateRule(tx, req, ptype, oldRules[i], newRules[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-func (a *adapter) UpdateFilteredPolicies(string, string, [][]string, int, ...string) ([][]string, error) {
-	return nil, errors.New("not implemented")
+// UpdateFilteredPolicies replaces the policy rules that match the filter with the new rules in the storage, it returns
+// the replaced rules. The replaced rules are removed & the new rules are added, as their numbers may differ.
+func (a *adapter) UpdateFilteredPolicies(
+	_ string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string,
+) (oldRules [][]string, err error) {
+	req := adapterRequest("UpdateFilteredPolicies")
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if oldRules, err = removeFilteredRules(tx, req, ptype, fieldIndex, fieldValues...); err != nil {
+			return err
+		}
+		return addRules(tx, req, ptype, newRules)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return oldRules, nil
 }
 
 // LoadPolicy loads all policy rules from the storage.
@@ -75,34 +123,71 @@ func (a *adapter) LoadPolicy(model model.Model) error {
 	return nil
 }
 
-// SavePolicy saves all policy rules to the storage.
-func (a *adapter) SavePolicy(_ model.Model) error {
-	return errors.New("not implemented")
+// SavePolicy saves all policy rules to the storage, the rules not in the model are removed. Only the policy types of
+// ruleLengths are stored, the rules of the other policy types of the model, e.g. p2 or g2, are skipped.
+func (a *adapter) SavePolicy(m model.Model) error {
+	req := adapterRequest("SavePolicy")
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		for _, sec := range []string{permissionTag, groupTag} {
+			for ptype, assertion := range m[sec] {
+				if _, ok := ruleLengths[ptype]; !ok {
+					continue
+				}
+				if err := saveRules(tx, req, ptype, assertion.Policy); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
 }
 
 // AddPolicy adds a policy rule to the storage.
-func (a *adapter) AddPolicy(string, string, []string) error {
-	return errors.New("not implemented")
+func (a *adapter) AddPolicy(_ string, ptype string, rule []string) error {
+	return a.AddPolicies("", ptype, [][]string{rule})
 }
 
 // AddPolicies adds policy rules to the storage.
-func (a *adapter) AddPolicies(string, string, [][]string) error {
-	return errors.New("not implemented")
+func (a *adapter) AddPolicies(_ string, ptype string, rules [][]string) error {
+	req := adapterRequest("AddPolicies")
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		return addRules(tx, req, ptype, rules)
+	})
 }
 
-// RemovePolicy removes a policy rule from the storage.
-func (a *adapter) RemovePolicy(string, string, []string) error {
-	return errors.New("not implemented")
+// RemovePolicy removes a policy rule from the storage, a rule not in the storage is a NotFound error.
+func (a *adapter) RemovePolicy(_ string, ptype string, rule []string) error {
+	return a.RemovePolicies("", ptype, [][]string{rule})
 }
 
-// RemovePolicies removes policy rules from the storage.
-func (a *adapter) RemovePolicies(string, string, [][]string) error {
-	return errors.New("not implemented")
+// RemovePolicies removes policy rules from the storage, a rule not in the storage is a NotFound error.
+func (a *adapter) RemovePolicies(_ string, ptype string, rules [][]string) error {
+	req := adapterRequest("RemovePolicies")
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		for _, rule := range rules {
+			if err := removeRule(tx, req, ptype, rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // RemoveFilteredPolicy removes policy rules that match the filter from the storage.
-func (a *adapter) RemoveFilteredPolicy(string, string, int, ...string) error {
-	return errors.New("not implemented")
+func (a *adapter) RemoveFilteredPolicy(_ string, ptype string, fieldIndex int, fieldValues ...string) error {
+	req := adapterRequest("RemoveFilteredPolicy")
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		_, err := removeFilteredRules(tx, req, ptype, fieldIndex, fieldValues...)
+		return err
+	})
+}
+
+// adapterRequest returns the request of the changes made by an operation of the adapter, which are recorded with
+// adapterActor as their requester
+func adapterRequest(op string) *audit.Request {
+	requestedBy := adapterActor
+	reason := op + " of the Casbin adapter"
+	return &audit.Request{RequestedBy: &requestedBy, Reason: &reason}
 }
 
 func loadPolicyLine(permission *Permission, model model.Model) {
@@ -126,3 +211,443 @@ func loadRoleLine(role *Role, model model.Model) {
 		persist.LoadPolicyArray(g, model)
 	}
 }
+
+// validateRule checks the policy type & the fields of a rule
+func validateRule(ptype string, rule []string) error {
+	length, ok := ruleLengths[ptype]
+	if !ok {
+		return errors.New(errors.NotSupported, fmt.Sprintf("policy type %s is not supported", ptype))
+	}
+	if len(rule) != length {
+		return errors.New(errors.BadRequest, fmt.Sprintf("the rules of policy type %s have %d fields", ptype, length))
+	}
+	for _, field := range rule {
+		if field == "" {
+			return errors.New(errors.BadRequest, fmt.Sprintf("rule %v has an empty field", rule))
+		}
+	}
+	return nil
+}
+
+// loadRules returns the rules of a policy type in the storage
+func loadRules(tx *gorm.DB, ptype string) ([][]string, error) {
+	var rules [][]string
+	switch ptype {
+	case permissionTag:
+		var permissions []*Permission
+		if err := tx.Preload("Role").Order("role_id").Find(&permissions).Error; err != nil {
+			return nil, err
+		}
+		for _, permission := range permissions {
+			rules = append(rules, []string{permission.RoleName(), permission.Resource, permission.Method})
+		}
+	case groupTag:
+		var roles []*Role
+		if err := tx.Preload("Users").Find(&roles).Error; err != nil {
+			return nil, err
+		}
+		for _, role := range roles {
+			for _, user := range role.Users {
+				rules = append(rules, []string{user.Email, role.Name})
+			}
+		}
+	default:
+		return nil, validateRule(ptype, nil)
+	}
+	return rules, nil
+}
+
+// saveRules makes the rules of a policy type in the storage the given rules
+func saveRules(tx *gorm.DB, req *audit.Request, ptype string, rules [][]string) error {
+	current, err := loadRules(tx, ptype)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		keep[ruleKey(rule)] = true
+	}
+	for _, rule := range current {
+		if !keep[ruleKey(rule)] {
+			if err = removeRule(tx, req, ptype, rule); err != nil {
+				return err
+			}
+		}
+	}
+	return addRules(tx, req, ptype, rules)
+}
+
+func addRules(tx *gorm.DB, req *audit.Request, ptype string, rules [][]string) error {
+	for _, rule := range rules {
+		if _, err := addRule(tx, req, ptype, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addRule adds a rule, it reports whether the rule is added, i.e. it's not in the storage already. The addition is
+// recorded as an action of req.
+func addRule(tx *gorm.DB, req *audit.Request, ptype string, rule []string) (bool, error) {
+	if err := validateRule(ptype, rule); err != nil {
+		return false, err
+	}
+
+	var err error
+	added := false
+	if ptype == permissionTag {
+		_, added, err = addPermission(tx, req, rule[0], rule[1], rule[2])
+	} else {
+		_, added, err = addUserRole(tx, req, rule[0], rule[1])
+	}
+	return added, err
+}
+
+// removeRule removes a rule, a rule not in the storage is a NotFound error. The removal is recorded as an action of
+// req.
+func removeRule(tx *gorm.DB, req *audit.Request, ptype string, rule []string) error {
+	if err := validateRule(ptype, rule); err != nil {
+		return err
+	}
+
+	var err error
+	if ptype == permissionTag {
+		_, err = removePermission(tx, req, rule[0], rule[1], rule[2])
+	} else {
+		_, err = removeUserRole(tx, req, rule[0], rule[1])
+	}
+	return err
+}
+
+// updateRule updates a rule in place, an old rule not in the storage is a NotFound error & a new rule already in the
+// storage is a Conflict error. The update is recorded as an action of req.
+func updateRule(tx *gorm.DB, req *audit.Request, ptype string, oldRule, newRule []string) error {
+	if err := validateRule(ptype, oldRule); err != nil {
+		return err
+	}
+	if err := validateRule(ptype, newRule); err != nil {
+		return err
+	}
+
+	var err error
+	if ptype == permissionTag {
+		_, err = updatePermission(tx, req, oldRule, newRule)
+	} else {
+		_, err = updateUserRole(tx, req, oldRule, newRule)
+	}
+	return err
+}
+
+// removeFilteredRules removes the rules matching the filter, an empty field value matches all values. It returns the
+// removed rules.
+func removeFilteredRules(
+	tx *gorm.DB, req *audit.Request, ptype string, fieldIndex int, fieldValues ...string,
+) ([][]string, error) {
+	rules, err := loadRules(tx, ptype)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed [][]string
+	for _, rule := range rules {
+		if !matchFilter(rule, fieldIndex, fieldValues) {
+			continue
+		}
+		if err = removeRule(tx, req, ptype, rule); err != nil {
+			return nil, err
+		}
+		removed = append(removed, rule)
+	}
+	return removed, nil
+}
+
+func matchFilter(rule []string, fieldIndex int, fieldValues []string) bool {
+	if fieldIndex < 0 || fieldIndex+len(fieldValues) > len(rule) {
+		return false
+	}
+	for i, value := range fieldValues {
+		if value != "" && rule[fieldIndex+i] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func ruleKey(rule []string) string {
+	return strings.Join(rule, ",")
+}
+
+// findRole returns the role of the name, or nil when it doesn't exist
+func findRole(tx *gorm.DB, name string) (*Role, error) {
+	var roles []*Role
+	if err := tx.Where("name = ?", name).Limit(1).Find(&roles).Error; err != nil || len(roles) == 0 {
+		return nil, err
+	}
+	return roles[0], nil
+}
+
+func findOrCreateRole(tx *gorm.DB, name string) (*Role, error) {
+	role, err := findRole(tx, name)
+	if err != nil || role != nil {
+		return role, err
+	}
+
+	role = &Role{Name: name}
+	return role, tx.Create(role).Error
+}
+
+// findUser returns the user of the email, or nil when it doesn't exist
+func findUser(tx *gorm.DB, email string) (*User, error) {
+	var users []*User
+	if err := tx.Where("email = ?", email).Limit(1).Find(&users).Error; err != nil || len(users) == 0 {
+		return nil, err
+	}
+	return users[0], nil
+}
+
+func findOrCreateUser(tx *gorm.DB, email string) (*User, error) {
+	user, err := findUser(tx, email)
+	if err != nil || user != nil {
+		return user, err
+	}
+
+	user = &User{Email: email}
+	return user, tx.Create(user).Error
+}
+
+// addPermission adds a permission to a role, the role is created when it doesn't exist. It reports whether the
+// permission is added, i.e. the role doesn't have it already, the addition is recorded as an action of req.
+func addPermission(tx *gorm.DB, req *audit.Request, roleName, resource, method string) (*Permission, bool, error) {
+	role, err := findOrCreateRole(tx, roleName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var permissions []*Permission
+	err = tx.Where("role_id = ? AND resource = ? AND method = ?", role.ID, resource, method).
+		Limit(1).Find(&permissions).Error
+	if err != nil || len(permissions) > 0 {
+		return firstOrNil(permissions), false, err
+	}
+
+	permission := &Permission{RoleID: role.ID, Resource: resource, Method: method}
+	if err = tx.Create(permission).Error; err != nil {
+		return nil, false, err
+	}
+	return permission, true, recordPermissionAction(tx, req, permission, audit.Create)
+}
+
+// findPermission returns the permission of a role, a permission the role doesn't have is a NotFound error
+func findPermission(tx *gorm.DB, roleName, resource, method string) (*Permission, error) {
+	role, err := findRole(tx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []*Permission
+	if role != nil {
+		err = tx.Where("role_id = ? AND resource = ? AND method = ?", role.ID, resource, method).
+			Limit(1).Find(&permissions).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(permissions) == 0 {
+		return nil, errors.New(errors.NotFound,
+			fmt.Sprintf("role %s doesn't have the permission to %s %s", roleName, method, resource))
+	}
+	return permissions[0], nil
+}
+
+// removePermission removes a permission from a role, it returns the removed permission, a permission the role doesn't
+// have is a NotFound error. The removal is recorded as an action of req.
+func removePermission(tx *gorm.DB, req *audit.Request, roleName, resource, method string) (*Permission, error) {
+	permission, err := findPermission(tx, roleName, resource, method)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Delete(permission).Error; err != nil {
+		return nil, err
+	}
+	return permission, recordPermissionAction(tx, req, permission, audit.Delete)
+}
+
+// updatePermission updates a permission in place from the old rule to the new rule, the role of the new rule is
+// created when it doesn't exist. It returns the updated permission, an old rule not in the storage is a NotFound error
+// & a new rule already in the storage is a Conflict error. The update is recorded as an action of req.
+func updatePermission(tx *gorm.DB, req *audit.Request, oldRule, newRule []string) (*Permission, error) {
+	permission, err := findPermission(tx, oldRule[0], oldRule[1], oldRule[2])
+	if err != nil {
+		return nil, err
+	}
+	role, err := findOrCreateRole(tx, newRule[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []*Permission
+	err = tx.Where("role_id = ? AND resource = ? AND method = ? AND id <> ?", role.ID, newRule[1], newRule[2],
+		permission.ID).Limit(1).Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(permissions) > 0 {
+		return nil, errors.New(errors.Conflict,
+			fmt.Sprintf("role %s already has the permission to %s %s", newRule[0], newRule[2], newRule[1]))
+	}
+
+	permission.RoleID, permission.Resource, permission.Method = role.ID, newRule[1], newRule[2]
+	if err = tx.Save(permission).Error; err != nil {
+		return nil, err
+	}
+	return permission, recordPermissionAction(tx, req, permission, audit.Update)
+}
+
+// addUserRole adds a role to a user, the user & the role are created when they don't exist. It reports whether the
+// role is added, i.e. the user doesn't have it already, the addition is recorded as an action of req.
+func addUserRole(tx *gorm.DB, req *audit.Request, email, roleName string) (*UserRoles, bool, error) {
+	user, err := findOrCreateUser(tx, email)
+	if err != nil {
+		return nil, false, err
+	}
+	role, err := findOrCreateRole(tx, roleName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var userRoles []*UserRoles
+	err = tx.Where("role_id = ? AND user_id = ?", role.ID, user.ID).Limit(1).Find(&userRoles).Error
+	if err != nil || len(userRoles) > 0 {
+		return firstOrNil(userRoles), false, err
+	}
+
+	userRole := &UserRoles{RoleID: role.ID, UserID: user.ID}
+	if err = tx.Create(userRole).Error; err != nil {
+		return nil, false, err
+	}
+	return userRole, true, recordUserRoleAction(tx, req, userRole, audit.Create)
+}
+
+// findUserRole returns the role of a user, a role the user doesn't have is a NotFound error
+func findUserRole(tx *gorm.DB, email, roleName string) (*UserRoles, error) {
+	user, err := findUser(tx, email)
+	if err != nil {
+		return nil, err
+	}
+	role, err := findRole(tx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	var userRoles []*UserRoles
+	if user != nil && role != nil {
+		err = tx.Where("role_id = ? AND user_id = ?", role.ID, user.ID).Limit(1).Find(&userRoles).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(userRoles) == 0 {
+		return nil, errors.New(errors.NotFound, fmt.Sprintf("user %s doesn't have the role %s", email, roleName))
+	}
+	return userRoles[0], nil
+}
+
+// removeUserRole removes a role from a user, it returns the removed user role, a role the user doesn't have is a
+// NotFound error. The removal is recorded as an action of req.
+func removeUserRole(tx *gorm.DB, req *audit.Request, email, roleName string) (*UserRoles, error) {
+	userRole, err := findUserRole(tx, email, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Delete(userRole).Error; err != nil {
+		return nil, err
+	}
+	return userRole, recordUserRoleAction(tx, req, userRole, audit.Delete)
+}
+
+// updateUserRole updates a user role in place from the old rule to the new rule, the user & the role of the new rule
+// are created when they don't exist. It returns the updated user role, an old rule not in the storage is a NotFound
+// error & a new rule already in the storage is a Conflict error. The update is recorded as an action of req.
+func updateUserRole(tx *gorm.DB, req *audit.Request, oldRule, newRule []string) (*UserRoles, error) {
+	userRole, err := findUserRole(tx, oldRule[0], oldRule[1])
+	if err != nil {
+		return nil, err
+	}
+	user, err := findOrCreateUser(tx, newRule[0])
+	if err != nil {
+		return nil, err
+	}
+	role, err := findOrCreateRole(tx, newRule[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var userRoles []*UserRoles
+	err = tx.Where("role_id = ? AND user_id = ? AND id <> ?", role.ID, user.ID, userRole.ID).
+		Limit(1).Find(&userRoles).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(userRoles) > 0 {
+		return nil, errors.New(errors.Conflict, fmt.Sprintf("user %s already has the role %s", newRule[0], newRule[1]))
+	}
+
+	userRole.RoleID, userRole.UserID = role.ID, user.ID
+	if err = tx.Save(userRole).Error; err != nil {
+		return nil, err
+	}
+	return userRole, recordUserRoleAction(tx, req, userRole, audit.Update)
+}
+
+// recordPermissionAction records a change of a permission, see validateRequest for the requirements of req
+func recordPermissionAction(
+	tx *gorm.DB, req *audit.Request, permission *Permission, actionType audit.ActionType,
+) error {
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+	return tx.Create(&PermissionAction{
+		PermissionID: permission.ID,
+		RoleID:       permission.RoleID,
+		Resource:     permission.Resource,
+		Method:       permission.Method,
+		ActionEntity: actionEntity(req, actionType),
+	}).Error
+}
+
+// recordUserRoleAction records a change of a user role, see validateRequest for the requirements of req
+func recordUserRoleAction(tx *gorm.DB, req *audit.Request, userRole *UserRoles, actionType audit.ActionType) error {
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+	return tx.Create(&UserRoleAction{
+		UserRoleID:   userRole.ID,
+		RoleID:       userRole.RoleID,
+		UserID:       userRole.UserID,
+		ActionEntity: actionEntity(req, actionType),
+	}).Error
+}
+
+// validateRequest checks the request of a change has a requester & a reason, so every action is attributed
+func validateRequest(req *audit.Request) error {
+	if req == nil || req.RequestedBy == nil || *req.RequestedBy == "" || req.Reason == nil || *req.Reason == "" {
+		return errors.New(errors.BadRequest, "the requester & the reason of the change are required")
+	}
+	return nil
+}
+
+func actionEntity(req *audit.Request, actionType audit.ActionType) audit.ActionEntity {
+	return audit.ActionEntity{
+		UpdatedBy:    req.RequestedBy,
+		UpdateReason: req.Reason,
+		ActionType:   actionType,
+	}
+}
+
+func firstOrNil[T any](values []*T) *T {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
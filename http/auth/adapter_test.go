@@ -0,0 +1,385 @@
+package auth
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/audit"
+	"github.com/wego/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const testModelConf = "auth_model.conf.example"
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each connection has its own in-memory database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err = db.SetupJoinTable(&Role{}, "Users", &UserRoles{}); err != nil {
+		t.Fatal(err)
+	}
+	err = db.AutoMigrate(&Role{}, &User{}, &UserRoles{}, &Permission{}, &PermissionAction{}, &UserRoleAction{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newTestModel(t *testing.T) model.Model {
+	t.Helper()
+
+	conf, err := os.ReadFile(testModelConf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := model.NewModelFromString(string(conf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func Test_adapter_AddRemovePolicy(t *testing.T) {
+	tests := map[string]struct {
+		ptype string
+		rule  []string
+	}{
+		"permission": {ptype: permissionTag, rule: []string{"admin", "/bookings", "POST"}},
+		"user role":  {ptype: groupTag, rule: []string{"someone@wego.com", "admin"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			a := newAdapter(newTestDB(t))
+
+			assert.NoError(a.AddPolicy("", test.ptype, test.rule))
+			assert.NoError(a.AddPolicy("", test.ptype, test.rule), "adding a rule twice is a no-op")
+			rules, err := loadRules(a.db, test.ptype)
+			assert.NoError(err)
+			assert.Equal([][]string{test.rule}, rules)
+
+			assert.NoError(a.RemovePolicy("", test.ptype, test.rule))
+			rules, err = loadRules(a.db, test.ptype)
+			assert.NoError(err)
+			assert.Empty(rules)
+
+			err = a.RemovePolicy("", test.ptype, test.rule)
+			assert.Equal(http.StatusNotFound, errors.Code(err), "removing a missing rule is an error")
+		})
+	}
+}
+
+func Test_adapter_UpdatePolicy(t *testing.T) {
+	assert := assert.New(t)
+	a := newAdapter(newTestDB(t))
+
+	assert.NoError(a.AddPolicies("", permissionTag, [][]string{
+		{"admin", "/bookings", "POST"},
+		{"admin", "/bookings", "GET"},
+	}))
+	err := a.UpdatePolicy("", permissionTag, []string{"admin", "/bookings", "POST"}, []string{"ops", "/refunds", "POST"})
+	assert.NoError(err)
+
+	rules, err := loadRules(a.db, permissionTag)
+	assert.NoError(err)
+	assert.ElementsMatch([][]string{{"admin", "/bookings", "GET"}, {"ops", "/refunds", "POST"}}, rules)
+
+	err = a.UpdatePolicy("", permissionTag, []string{"admin", "/bookings", "POST"}, []string{"ops", "/bookings", "POST"})
+	assert.Equal(http.StatusNotFound, errors.Code(err))
+	rules, err = loadRules(a.db, permissionTag)
+	assert.NoError(err)
+	assert.Len(rules, 2, "a failed update is rolled back")
+
+	err = a.UpdatePolicy("", permissionTag, []string{"ops", "/refunds", "POST"}, []string{"admin", "/bookings", "GET"})
+	assert.Equal(http.StatusConflict, errors.Code(err), "updating a rule to another rule is an error")
+
+	var count int64
+	assert.NoError(a.db.Unscoped().Model(&Permission{}).Count(&count).Error)
+	assert.EqualValues(2, count, "the rules are updated in place")
+}
+
+func Test_adapter_UpdatePolicy_UserRole(t *testing.T) {
+	assert := assert.New(t)
+	a := newAdapter(newTestDB(t))
+
+	assert.NoError(a.AddPolicies("", groupTag, [][]string{{"someone@wego.com", "admin"}, {"other@wego.com", "ops"}}))
+	assert.NoError(a.UpdatePolicy("", groupTag, []string{"someone@wego.com", "admin"}, []string{"new@wego.com", "ops"}))
+
+	rules, err := loadRules(a.db, groupTag)
+	assert.NoError(err)
+	assert.ElementsMatch([][]string{{"new@wego.com", "ops"}, {"other@wego.com", "ops"}}, rules)
+
+	err = a.UpdatePolicy("", groupTag, []string{"new@wego.com", "ops"}, []string{"other@wego.com", "ops"})
+	assert.Equal(http.StatusConflict, errors.Code(err))
+
+	var count int64
+	assert.NoError(a.db.Unscoped().Model(&UserRoles{}).Count(&count).Error)
+	assert.EqualValues(2, count, "the rules are updated in place")
+}
+
+func Test_adapter_RemovePolicies(t *testing.T) {
+	assert := assert.New(t)
+	a := newAdapter(newTestDB(t))
+
+	assert.NoError(a.AddPolicies("", permissionTag, [][]string{
+		{"admin", "/bookings", "POST"},
+		{"admin", "/bookings", "GET"},
+		{"ops", "/refunds", "POST"},
+	}))
+	assert.NoError(a.RemovePolicies("", permissionTag, [][]string{
+		{"admin", "/bookings", "POST"},
+		{"ops", "/refunds", "POST"},
+	}))
+	rules, err := loadRules(a.db, permissionTag)
+	assert.NoError(err)
+	assert.Equal([][]string{{"admin", "/bookings", "GET"}}, rules)
+
+	err = a.RemovePolicies("", permissionTag, [][]string{{"admin", "/bookings", "GET"}, {"ops", "/refunds", "POST"}})
+	assert.Equal(http.StatusNotFound, errors.Code(err))
+	rules, err = loadRules(a.db, permissionTag)
+	assert.NoError(err)
+	assert.Len(rules, 1, "a failed removal is rolled back")
+}
+
+func Test_adapter_RemoveFilteredPolicy(t *testing.T) {
+	permissions := [][]string{
+		{"admin", "/bookings", "POST"},
+		{"admin", "/bookings", "GET"},
+		{"admin", "/refunds", "POST"},
+		{"ops", "/refunds", "POST"},
+	}
+	tests := map[string]struct {
+		fieldIndex  int
+		fieldValues []string
+		want        [][]string
+	}{
+		"by role": {
+			fieldIndex:  0,
+			fieldValues: []string{"admin"},
+			want:        [][]string{{"ops", "/refunds", "POST"}},
+		},
+		"by resource & method": {
+			fieldIndex:  1,
+			fieldValues: []string{"/refunds", "POST"},
+			want:        [][]string{{"admin", "/bookings", "POST"}, {"admin", "/bookings", "GET"}},
+		},
+		"an empty value matches all values": {
+			fieldIndex:  0,
+			fieldValues: []string{"admin", "", "POST"},
+			want:        [][]string{{"admin", "/bookings", "GET"}, {"ops", "/refunds", "POST"}},
+		},
+		"no match": {
+			fieldIndex:  0,
+			fieldValues: []string{"support"},
+			want:        permissions,
+		},
+		"out of range": {
+			fieldIndex:  2,
+			fieldValues: []string{"POST", "admin"},
+			want:        permissions,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			a := newAdapter(newTestDB(t))
+			assert.NoError(a.AddPolicies("", permissionTag, permissions))
+
+			assert.NoError(a.RemoveFilteredPolicy("", permissionTag, test.fieldIndex, test.fieldValues...))
+			rules, err := loadRules(a.db, permissionTag)
+			assert.NoError(err)
+			assert.ElementsMatch(test.want, rules)
+		})
+	}
+}
+
+func Test_adapter_UpdateFilteredPolicies(t *testing.T) {
+	assert := assert.New(t)
+	a := newAdapter(newTestDB(t))
+
+	assert.NoError(a.AddPolicies("", groupTag, [][]string{
+		{"someone@wego.com", "admin"},
+		{"someone@wego.com", "ops"},
+		{"other@wego.com", "ops"},
+	}))
+	oldRules, err := a.UpdateFilteredPolicies("", groupTag, [][]string{{"someone@wego.com", "support"}}, 0,
+		"someone@wego.com", "")
+	assert.NoError(err)
+	assert.ElementsMatch([][]string{{"someone@wego.com", "admin"}, {"someone@wego.com", "ops"}}, oldRules)
+
+	rules, err := loadRules(a.db, groupTag)
+	assert.NoError(err)
+	assert.ElementsMatch([][]string{{"someone@wego.com", "support"}, {"other@wego.com", "ops"}}, rules)
+
+	_, err = a.UpdateFilteredPolicies("", groupTag, [][]string{{"other@wego.com", ""}}, 1, "ops")
+	assert.Equal(http.StatusBadRequest, errors.Code(err))
+	rules, err = loadRules(a.db, groupTag)
+	assert.NoError(err)
+	assert.Len(rules, 2, "a failed update is rolled back")
+}
+
+func Test_adapter_SavePolicy(t *testing.T) {
+	assert := assert.New(t)
+	a := newAdapter(newTestDB(t))
+
+	assert.NoError(a.AddPolicy("", permissionTag, []string{"admin", "/bookings", "POST"}))
+	assert.NoError(a.AddPolicy("", groupTag, []string{"someone@wego.com", "admin"}))
+
+	m := newTestModel(t)
+	assert.NoError(m.AddPolicy(permissionTag, permissionTag, []string{"ops", "/refunds", "POST"}))
+	assert.NoError(m.AddPolicy(groupTag, groupTag, []string{"someone@wego.com", "ops"}))
+	assert.NoError(a.SavePolicy(m))
+
+	loaded := newTestModel(t)
+	assert.NoError(a.LoadPolicy(loaded))
+	permissions, err := loaded.GetPolicy(permissionTag, permissionTag)
+	assert.NoError(err)
+	assert.Equal([][]string{{"ops", "/refunds", "POST"}}, permissions)
+	userRoles, err := loaded.GetPolicy(groupTag, groupTag)
+	assert.NoError(err)
+	assert.Equal([][]string{{"someone@wego.com", "ops"}}, userRoles)
+}
+
+func Test_adapter_SavePolicy_SkipsUnknownPolicyTypes(t *testing.T) {
+	assert := assert.New(t)
+	a := newAdapter(newTestDB(t))
+
+	m := newTestModel(t)
+	m.AddDef(permissionTag, "p2", "sub, obj")
+	assert.NoError(m.AddPolicy(permissionTag, permissionTag, []string{"ops", "/refunds", "POST"}))
+	assert.NoError(m.AddPolicy(permissionTag, "p2", []string{"ops", "/refunds"}))
+	assert.NoError(a.SavePolicy(m))
+
+	rules, err := loadRules(a.db, permissionTag)
+	assert.NoError(err)
+	assert.Equal([][]string{{"ops", "/refunds", "POST"}}, rules)
+}
+
+func Test_adapter_ReAddRemovedPolicy(t *testing.T) {
+	assert := assert.New(t)
+	a := newAdapter(newTestDB(t))
+	permission := []string{"admin", "/bookings", "POST"}
+	userRole := []string{"someone@wego.com", "admin"}
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(a.AddPolicy("", permissionTag, permission))
+		assert.NoError(a.AddPolicy("", groupTag, userRole))
+		assert.NoError(a.RemovePolicy("", permissionTag, permission))
+		assert.NoError(a.RemovePolicy("", groupTag, userRole))
+	}
+	assert.NoError(a.AddPolicy("", permissionTag, permission))
+	assert.NoError(a.AddPolicy("", groupTag, userRole))
+
+	m := newTestModel(t)
+	assert.NoError(a.LoadPolicy(m))
+	permissions, err := m.GetPolicy(permissionTag, permissionTag)
+	assert.NoError(err)
+	assert.Equal([][]string{permission}, permissions)
+	userRoles, err := m.GetPolicy(groupTag, groupTag)
+	assert.NoError(err)
+	assert.Equal([][]string{userRole}, userRoles)
+
+	var count int64
+	assert.NoError(a.db.Unscoped().Model(&Permission{}).Count(&count).Error)
+	assert.EqualValues(3, count, "the removed permissions are soft deleted")
+	assert.NoError(a.db.Unscoped().Model(&UserRoles{}).Count(&count).Error)
+	assert.EqualValues(3, count, "the removed user roles are soft deleted")
+}
+
+func Test_adapter_Actions(t *testing.T) {
+	assert := assert.New(t)
+	a := newAdapter(newTestDB(t))
+	permission := []string{"admin", "/bookings", "POST"}
+	userRole := []string{"someone@wego.com", "admin"}
+
+	assert.NoError(a.AddPolicy("", permissionTag, permission))
+	assert.NoError(a.AddPolicy("", groupTag, userRole))
+	assert.NoError(a.UpdatePolicy("", permissionTag, permission, []string{"admin", "/bookings", "GET"}))
+	assert.NoError(a.RemovePolicy("", groupTag, userRole))
+
+	type action struct {
+		actionType audit.ActionType
+		updatedBy  string
+		reason     string
+	}
+	var permissionActions []*PermissionAction
+	assert.NoError(a.db.Order("id").Find(&permissionActions).Error)
+	var actions []action
+	for _, pa := range permissionActions {
+		actions = append(actions, action{pa.ActionType, *pa.UpdatedBy, *pa.UpdateReason})
+	}
+	assert.Equal([]action{
+		{audit.Create, adapterActor, "AddPolicies of the Casbin adapter"},
+		{audit.Update, adapterActor, "UpdatePolicies of the Casbin adapter"},
+	}, actions)
+	if assert.Len(permissionActions, 2) {
+		assert.Equal("POST", permissionActions[0].Method)
+		assert.Equal("GET", permissionActions[1].Method)
+		assert.Equal(permissionActions[0].PermissionID, permissionActions[1].PermissionID, "the rule is updated in place")
+	}
+
+	var userRoleActions []*UserRoleAction
+	assert.NoError(a.db.Order("id").Find(&userRoleActions).Error)
+	actions = nil
+	for _, ua := range userRoleActions {
+		actions = append(actions, action{ua.ActionType, *ua.UpdatedBy, *ua.UpdateReason})
+	}
+	assert.Equal([]action{
+		{audit.Create, adapterActor, "AddPolicies of the Casbin adapter"},
+		{audit.Delete, adapterActor, "RemovePolicies of the Casbin adapter"},
+	}, actions)
+}
+
+func Test_Authorizer_GrantRevoke(t *testing.T) {
+	assert := assert.New(t)
+	db := newTestDB(t)
+	authorizer, err := NewAuthorizer(testModelConf, db, func(r *http.Request) (string, error) {
+		return r.Header.Get("X-User"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestedBy, reason := "admin@wego.com", "on-call access"
+	req := audit.Request{RequestedBy: &requestedBy, Reason: &reason}
+	assert.NoError(authorizer.GrantPermission("ops", "/refunds", "POST", req))
+	assert.NoError(authorizer.GrantRole("someone@wego.com", "ops", req))
+
+	allowed, err := authorizer.enforcer.Enforce("someone@wego.com", "/refunds", "POST")
+	assert.NoError(err)
+	assert.True(allowed)
+
+	var userRoleActions []*UserRoleAction
+	assert.NoError(db.Find(&userRoleActions).Error)
+	if assert.Len(userRoleActions, 1) {
+		assert.Equal(requestedBy, *userRoleActions[0].UpdatedBy)
+		assert.Equal(reason, *userRoleActions[0].UpdateReason)
+	}
+
+	err = authorizer.GrantRole("other@wego.com", "ops", audit.Request{})
+	assert.Equal(http.StatusBadRequest, errors.Code(err), "a change without requester & reason is rejected")
+	err = authorizer.RevokeRole("other@wego.com", "ops", req)
+	assert.Equal(http.StatusNotFound, errors.Code(err))
+
+	assert.NoError(authorizer.RevokeRole("someone@wego.com", "ops", req))
+	allowed, err = authorizer.enforcer.Enforce("someone@wego.com", "/refunds", "POST")
+	assert.NoError(err)
+	assert.False(allowed)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/wego/pkg/audit"
 	"github.com/wego/pkg/errors"
 	"gorm.io/gorm"
 )
@@ -23,6 +24,7 @@ var (
 // Authorizer an RBAC authorizer
 type Authorizer struct {
 	enforcer    *casbin.Enforcer
+	db          *gorm.DB
 	userHandler func(r *http.Request) (string, error)
 }
 
@@ -36,6 +38,7 @@ func NewAuthorizer(conf string, db *gorm.DB, userHandler func(r *http.Request) (
 
 	return &Authorizer{
 		enforcer:    e,
+		db:          db,
 		userHandler: userHandler,
 	}, nil
 }
@@ -54,6 +57,56 @@ func (a *Authorizer) LoadPolicy() error {
 	return a.enforcer.LoadPolicy()
 }
 
+// GrantRole grants a role to a user, the user & the role are created when they don't exist.
+// The grant is recorded as an action of req, & the policy is reloaded.
+func (a *Authorizer) GrantRole(user, role string, req audit.Request) error {
+	return a.change(groupTag, []string{user, role}, &req, func(tx *gorm.DB) error {
+		_, _, err := addUserRole(tx, &req, user, role)
+		return err
+	})
+}
+
+// RevokeRole revokes a role from a user, a role the user doesn't have is a NotFound error. The revocation is recorded
+// as an action of req, & the policy is reloaded.
+func (a *Authorizer) RevokeRole(user, role string, req audit.Request) error {
+	return a.change(groupTag, []string{user, role}, &req, func(tx *gorm.DB) error {
+		_, err := removeUserRole(tx, &req, user, role)
+		return err
+	})
+}
+
+// GrantPermission grants a role the permission to call a method on a resource, the role is created when it doesn't
+// exist. The grant is recorded as an action of req, & the policy is reloaded.
+func (a *Authorizer) GrantPermission(role, resource, method string, req audit.Request) error {
+	return a.change(permissionTag, []string{role, resource, method}, &req, func(tx *gorm.DB) error {
+		_, _, err := addPermission(tx, &req, role, resource, method)
+		return err
+	})
+}
+
+// RevokePermission revokes the permission to call a method on a resource from a role, a permission the role doesn't
+// have is a NotFound error. The revocation is recorded as an action of req, & the policy is reloaded.
+func (a *Authorizer) RevokePermission(role, resource, method string, req audit.Request) error {
+	return a.change(permissionTag, []string{role, resource, method}, &req, func(tx *gorm.DB) error {
+		_, err := removePermission(tx, &req, role, resource, method)
+		return err
+	})
+}
+
+// change validates the rule & the request, makes the change in a transaction & reloads the policy
+func (a *Authorizer) change(ptype string, rule []string, req *audit.Request, fn func(tx *gorm.DB) error) error {
+	if err := validateRule(ptype, rule); err != nil {
+		return err
+	}
+	if err := validateRequest(req); err != nil {
+		return err
+	}
+	if err := a.db.Transaction(fn); err != nil {
+		return err
+	}
+	return a.enforcer.LoadPolicy()
+}
+
 // checkPermission checks the user/path/method combination from the request.
 // Returns nil (permission granted) or error (permission denied)
 func (a *Authorizer) checkPermission(r *http.Request) error {
@@ -1,6 +1,9 @@
 package auth
 
-import "gorm.io/gorm"
+import (
+	"github.com/wego/pkg/audit"
+	"gorm.io/gorm"
+)
 
 // Role ...
 type Role struct {
@@ -58,3 +61,32 @@ func (p *Permission) RoleName() (name string) {
 func (p *Permission) TableName() string {
 	return "auth_role_permissions"
 }
+
+// PermissionAction is a change of a permission
+type PermissionAction struct {
+	gorm.Model
+	PermissionID uint
+	RoleID       uint
+	Resource     string
+	Method       string
+	audit.ActionEntity
+}
+
+// TableName return the table name
+func (a *PermissionAction) TableName() string {
+	return "auth_role_permission_actions"
+}
+
+// UserRoleAction is a change of a user role
+type UserRoleAction struct {
+	gorm.Model
+	UserRoleID uint
+	RoleID     uint
+	UserID     uint
+	audit.ActionEntity
+}
+
+// TableName return the table name
+func (a *UserRoleAction) TableName() string {
+	return "auth_user_role_actions"
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS auth_role_permission_actions;
+DROP TABLE IF EXISTS auth_user_role_actions;
+DROP INDEX IF EXISTS idx_auth_role_permission_actions_permission_id;
+DROP INDEX IF EXISTS idx_auth_user_role_actions_user_role_id;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS auth_role_permission_actions
+(
+    id            BIGSERIAL PRIMARY KEY,
+    permission_id BIGINT REFERENCES auth_role_permissions (id) NOT NULL,
+    role_id       BIGINT REFERENCES auth_roles (id)            NOT NULL,
+    resource      TEXT                                         NOT NULL,
+    method        TEXT                                         NOT NULL,
+    updated_by    TEXT                                         NOT NULL,
+    update_reason TEXT                                         NOT NULL,
+    action_type   TEXT                                         NOT NULL,
+    created_at    TIMESTAMP,
+    updated_at    TIMESTAMP,
+    deleted_at    TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS auth_user_role_actions
+(
+    id            BIGSERIAL PRIMARY KEY,
+    user_role_id  BIGINT REFERENCES auth_user_roles (id) NOT NULL,
+    role_id       BIGINT REFERENCES auth_roles (id)      NOT NULL,
+    user_id       BIGINT REFERENCES auth_users (id)      NOT NULL,
+    updated_by    TEXT                                   NOT NULL,
+    update_reason TEXT                                   NOT NULL,
+    action_type   TEXT                                   NOT NULL,
+    created_at    TIMESTAMP,
+    updated_at    TIMESTAMP,
+    deleted_at    TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_role_permission_actions_permission_id ON auth_role_permission_actions (permission_id);
+CREATE INDEX IF NOT EXISTS idx_auth_user_role_actions_user_role_id ON auth_user_role_actions (user_role_id);
@@ -4,23 +4,35 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/wego/pkg/errors"
 )
 
+// Option configures the validator of gin, which is shared by all the engines
+type Option func(v *validator.Validate)
+
+// WithJSONFieldNames names the fields of the validation errors by their JSON names, so the paths of errors.FieldError
+// are the paths in the request body, e.g. "passengers[0].first_name" instead of "Passengers[0].FirstName"
+func WithJSONFieldNames() Option {
+	return func(v *validator.Validate) {
+		v.RegisterTagNameFunc(errors.JSONTagName)
+	}
+}
+
 // New returns a new gin engine with custom validators， used to replace gin.New()
-func New() *gin.Engine {
+func New(opts ...Option) *gin.Engine {
 	e := gin.New()
-	registerValidator()
+	registerValidator(opts)
 	return e
 }
 
 // Default returns a new gin engine with custom validators， used to replace gin.Default()
-func Default() *gin.Engine {
+func Default(opts ...Option) *gin.Engine {
 	e := gin.Default()
-	registerValidator()
+	registerValidator(opts)
 	return e
 }
 
-func registerValidator() {
+func registerValidator(opts []Option) {
 	for key, value := range fieldValidators {
 		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 			_ = v.RegisterValidation(key, value)
@@ -31,4 +43,9 @@ func registerValidator() {
 			v.RegisterStructValidation(value, key)
 		}
 	}
+	for _, opt := range opts {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			opt(v)
+		}
+	}
 }
@@ -9,7 +9,10 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"github.com/wego/pkg/errors"
 	"github.com/wego/pkg/http/wegin"
 )
 
@@ -480,3 +483,32 @@ func (s *EngineSuite) Test_OneOfOrBlank() {
 		})
 	}
 }
+
+type jsonFieldNamesTestStruct struct {
+	Passengers []struct {
+		FirstName string `json:"first_name" binding:"required"`
+	} `json:"passengers" binding:"dive"`
+}
+
+// Test_WithJSONFieldNames runs after the suite, as the tag name function is global & kept for the cached structs
+func Test_WithJSONFieldNames(t *testing.T) {
+	assert := assert.New(t)
+	gin.SetMode(gin.TestMode)
+
+	var bindErr error
+	router := wegin.New(wegin.WithJSONFieldNames())
+	router.POST("/bookings", func(c *gin.Context) {
+		var b jsonFieldNamesTestStruct
+		bindErr = c.ShouldBindJSON(&b)
+	})
+
+	r := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/bookings", strings.NewReader(`{"passengers":[{"first_name":""}]}`))
+	assert.NoError(err)
+	router.ServeHTTP(r, req)
+
+	var validationErrs validator.ValidationErrors
+	if assert.ErrorAs(bindErr, &validationErrs) && assert.Len(validationErrs, 1) {
+		assert.Equal("passengers[0].first_name", errors.FieldError{FieldError: validationErrs[0]}.Path())
+	}
+}
@@ -8,6 +8,31 @@ require (
 	github.com/stretchr/testify v1.10.0
 )
 
+require (
+	github.com/Ardesco/credit-card-generator v0.0.0-20201208233833-a7202c328b75 // indirect
+	github.com/DataDog/datadog-go v4.8.3+incompatible // indirect
+	github.com/Microsoft/go-winio v0.6.2 // indirect
+	github.com/fsnotify/fsnotify v1.8.0 // indirect
+	github.com/getsentry/sentry-go v0.31.1 // indirect
+	github.com/go-viper/mapstructure/v2 v2.4.0 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/nicksnyder/go-i18n/v2 v2.5.1 // indirect
+	github.com/sagikazarmark/locafero v0.8.0 // indirect
+	github.com/sourcegraph/conc v0.3.0 // indirect
+	github.com/spf13/afero v1.14.0 // indirect
+	github.com/spf13/cast v1.7.1 // indirect
+	github.com/spf13/pflag v1.0.6 // indirect
+	github.com/spf13/viper v1.20.0 // indirect
+	github.com/subosito/gotenv v1.6.0 // indirect
+	github.com/wego/pkg/collection v0.1.11 // indirect
+	github.com/wego/pkg/common v0.1.18 // indirect
+	github.com/wego/pkg/env v0.1.1 // indirect
+	github.com/wego/pkg/pointer v0.1.2 // indirect
+	go.uber.org/multierr v1.11.0 // indirect
+	gorm.io/gorm v1.25.12 // indirect
+)
+
 require (
 	github.com/bytedance/sonic v1.13.1 // indirect
 	github.com/bytedance/sonic/loader v0.2.4 // indirect
@@ -28,6 +53,7 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
 	github.com/ugorji/go/codec v1.2.12 // indirect
+	github.com/wego/pkg/errors v0.2.4
 	golang.org/x/arch v0.15.0 // indirect
 	golang.org/x/crypto v0.54.0 // indirect
 	golang.org/x/net v0.56.0 // indirect
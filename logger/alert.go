@@ -0,0 +1,286 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	goErrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wego/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// maxDigestAttachments is the max number of attachments of a digest, one per title of the throttled messages
+const maxDigestAttachments = 20
+
+// UltronExTransport sends the UltronEx messages, the channel of the messages is set already
+type UltronExTransport interface {
+	Send(ctx context.Context, msg *UltronExMsg) error
+}
+
+// UltronExRoute routes the messages of a severity & request types to a channel
+type UltronExRoute struct {
+	// MinSeverity is the min severity of the messages
+	MinSeverity Severity
+	// RequestTypes are the request types of the messages, the route matches all request types when it's empty
+	RequestTypes []RequestType
+	Channel      string
+}
+
+func (r UltronExRoute) match(msg *UltronExMsg) bool {
+	if msg.Severity < r.MinSeverity {
+		return false
+	}
+	if len(r.RequestTypes) == 0 {
+		return true
+	}
+	for _, reqType := range r.RequestTypes {
+		if reqType == msg.RequestType {
+			return true
+		}
+	}
+	return false
+}
+
+// UltronExThrottle limits the number of messages sent to a channel
+type UltronExThrottle struct {
+	// Window is the duration of a throttling window, the messages are not throttled when it's 0
+	Window time.Duration
+	// Max is the number of messages sent in a window, 1 is used when it's 0. The other messages of the window are sent
+	// in a digest at the end of the window.
+	Max int
+}
+
+// UltronExConfig configures an UltronEx client
+type UltronExConfig struct {
+	// Routes are the routes of the messages without channel, the first matching route is used
+	Routes []UltronExRoute
+	// DefaultChannel is the channel of the messages without channel & matching route
+	DefaultChannel string
+	// Throttle is the throttling of the channels without their own throttling
+	Throttle UltronExThrottle
+	// Throttles are the throttling by channel
+	Throttles map[string]UltronExThrottle
+	// Transport sends the messages, UltronExFileTransport is used when it's nil
+	Transport UltronExTransport
+}
+
+// UltronEx is an alerting client, which routes, throttles & sends the UltronEx messages
+type UltronEx struct {
+	conf UltronExConfig
+
+	mu      sync.Mutex
+	windows map[string]*ultronExWindow
+}
+
+// ultronExWindow is a throttling window of a channel
+type ultronExWindow struct {
+	sent      int
+	throttled []*UltronExMsg
+	timer     *time.Timer
+}
+
+// NewUltronEx returns an UltronEx client
+func NewUltronEx(conf UltronExConfig) *UltronEx {
+	if conf.Transport == nil {
+		conf.Transport = UltronExFileTransport{}
+	}
+	return &UltronEx{conf: conf, windows: map[string]*ultronExWindow{}}
+}
+
+/*
+Alert sends a message to its channel, or to the channel of its route when it has no channel. The request type of the
+message is taken from RequestTypeFromContext when it's empty & the PII in its payloads are masked when the detection is
+enabled.
+
+The messages throttled in a window of the channel are sent in a digest at the end of the window, or by Flush.
+*/
+func (u *UltronEx) Alert(ctx context.Context, msg *UltronExMsg) error {
+	if msg == nil {
+		return nil
+	}
+
+	m := *detectUltronEx(msg)
+	if m.RequestType == "" {
+		m.RequestType = RequestTypeFromContext(ctx)
+	}
+	if m.Channel == "" {
+		m.Channel = u.route(&m)
+	}
+	if m.Channel == "" {
+		return errors.New(errors.BadRequest, fmt.Sprintf("no UltronEx channel for the message %q", m.Title))
+	}
+
+	if u.throttle(&m) {
+		return nil
+	}
+	return u.conf.Transport.Send(ctx, &m)
+}
+
+// Flush sends the digests of the current throttling windows & ends the windows
+func (u *UltronEx) Flush(ctx context.Context) error {
+	u.mu.Lock()
+	windows := u.windows
+	u.windows = map[string]*ultronExWindow{}
+	u.mu.Unlock()
+
+	var errs []error
+	for channel, w := range windows {
+		w.timer.Stop()
+		if err := u.sendDigest(ctx, channel, w.throttled); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return goErrors.Join(errs...)
+}
+
+func (u *UltronEx) route(msg *UltronExMsg) string {
+	for _, r := range u.conf.Routes {
+		if r.match(msg) {
+			return r.Channel
+		}
+	}
+	return u.conf.DefaultChannel
+}
+
+// throttle reports whether the message is throttled, the window of the channel is started by its first message
+func (u *UltronEx) throttle(msg *UltronExMsg) bool {
+	t, ok := u.conf.Throttles[msg.Channel]
+	if !ok {
+		t = u.conf.Throttle
+	}
+	if t.Window <= 0 {
+		return false
+	}
+	if t.Max <= 0 {
+		t.Max = 1
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	w := u.windows[msg.Channel]
+	if w == nil {
+		w = &ultronExWindow{}
+		channel := msg.Channel
+		w.timer = time.AfterFunc(t.Window, func() { u.endWindow(channel, w) })
+		u.windows[channel] = w
+	}
+	if w.sent < t.Max {
+		w.sent++
+		return false
+	}
+	w.throttled = append(w.throttled, msg)
+	return true
+}
+
+// endWindow sends the digest of a window when the window is not ended by Flush
+func (u *UltronEx) endWindow(channel string, w *ultronExWindow) {
+	u.mu.Lock()
+	if u.windows[channel] != w {
+		u.mu.Unlock()
+		return
+	}
+	delete(u.windows, channel)
+	u.mu.Unlock()
+
+	ctx := context.Background()
+	if err := u.sendDigest(ctx, channel, w.throttled); err != nil {
+		FromContext(ctx).Error("cannot send UltronEx digest", zap.String("channel", channel), zap.Error(err))
+	}
+}
+
+// sendDigest sends the throttled messages of a channel in a message with an attachment by title
+func (u *UltronEx) sendDigest(ctx context.Context, channel string, throttled []*UltronExMsg) error {
+	if len(throttled) == 0 {
+		return nil
+	}
+
+	digest := &UltronExMsg{
+		Channel: channel,
+		Text:    fmt.Sprintf("%d alerts were throttled", len(throttled)),
+	}
+	counts := map[string]int{}
+	var titles []string
+	for _, msg := range throttled {
+		if msg.Severity > digest.Severity {
+			digest.Severity = msg.Severity
+		}
+		if counts[msg.Title] == 0 {
+			titles = append(titles, msg.Title)
+		}
+		counts[msg.Title]++
+	}
+	if len(titles) > maxDigestAttachments {
+		digest.Text += fmt.Sprintf(", %d titles are not shown", len(titles)-maxDigestAttachments)
+		titles = titles[:maxDigestAttachments]
+	}
+	for _, title := range titles {
+		digest.Attachments = append(digest.Attachments, UltronExAttachment{
+			Title: title,
+			Text:  fmt.Sprintf("%d times", counts[title]),
+		})
+	}
+	return u.conf.Transport.Send(ctx, digest)
+}
+
+// UltronExFileTransport writes the messages to the UltronEx logger of Init, which is read by UltronEx
+type UltronExFileTransport struct{}
+
+// Send writes the message to the UltronEx logger
+func (UltronExFileTransport) Send(_ context.Context, msg *UltronExMsg) error {
+	logger := loggers[logTypeUltronex]
+	if logger != nil && msg != nil {
+		// UltronEx require the key as `msg`
+		logger.Info("", zap.Object("msg", msg))
+	}
+	return nil
+}
+
+// UltronExWebhookTransport posts the messages as JSON to a webhook, with their texts escaped & their titles, payloads
+// & fields in attachments colored by their severity
+type UltronExWebhookTransport struct {
+	URL string
+	// Headers are added to the requests, e.g. an authorization header
+	Headers map[string]string
+	// Client makes the requests, http.DefaultClient is used when it's nil
+	Client *http.Client
+}
+
+// Send posts the message to the webhook, a response status other than 2xx is an error
+func (t UltronExWebhookTransport) Send(ctx context.Context, msg *UltronExMsg) error {
+	body, err := json.Marshal(msg.format())
+	if err != nil {
+		return errors.New("cannot marshal UltronEx message", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("cannot create UltronEx webhook request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range t.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.New("cannot send UltronEx message", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return errors.New(fmt.Sprintf("UltronEx webhook responded with status %d", res.StatusCode))
+	}
+	return nil
+}
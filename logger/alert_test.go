@@ -0,0 +1,270 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+)
+
+// recordingTransport records the sent messages
+type recordingTransport struct {
+	mu   sync.Mutex
+	msgs []logger.UltronExMsg
+}
+
+func (r *recordingTransport) Send(_ context.Context, msg *logger.UltronExMsg) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, *msg)
+	return nil
+}
+
+func (r *recordingTransport) sent() []logger.UltronExMsg {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]logger.UltronExMsg(nil), r.msgs...)
+}
+
+func Test_UltronEx_Alert_Routes(t *testing.T) {
+	routes := []logger.UltronExRoute{
+		{MinSeverity: logger.SeverityCritical, Channel: "on-call"},
+		{MinSeverity: logger.SeverityError, RequestTypes: []logger.RequestType{"partner_search"}, Channel: "search"},
+	}
+	tests := map[string]struct {
+		ctx      context.Context
+		msg      logger.UltronExMsg
+		expected string
+	}{
+		"explicit channel": {
+			ctx:      context.Background(),
+			msg:      logger.UltronExMsg{Channel: "bookings", Severity: logger.SeverityCritical},
+			expected: "bookings",
+		},
+		"severity": {
+			ctx:      context.Background(),
+			msg:      logger.UltronExMsg{Severity: logger.SeverityCritical, RequestType: "partner_search"},
+			expected: "on-call",
+		},
+		"request type of the message": {
+			ctx:      context.Background(),
+			msg:      logger.UltronExMsg{Severity: logger.SeverityError, RequestType: "partner_search"},
+			expected: "search",
+		},
+		"request type of the context": {
+			ctx:      logger.ContextWithRequestType(context.Background(), "partner_search"),
+			msg:      logger.UltronExMsg{Severity: logger.SeverityError},
+			expected: "search",
+		},
+		"default channel": {
+			ctx:      context.Background(),
+			msg:      logger.UltronExMsg{Severity: logger.SeverityWarning, RequestType: "partner_search"},
+			expected: "alerts",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			transport := &recordingTransport{}
+			client := logger.NewUltronEx(logger.UltronExConfig{
+				Routes:         routes,
+				DefaultChannel: "alerts",
+				Transport:      transport,
+			})
+			assert.NoError(client.Alert(test.ctx, &test.msg))
+
+			sent := transport.sent()
+			if assert.Len(sent, 1) {
+				assert.Equal(test.expected, sent[0].Channel)
+			}
+		})
+	}
+}
+
+func Test_UltronEx_Alert_WithoutChannel(t *testing.T) {
+	assert := assert.New(t)
+
+	transport := &recordingTransport{}
+	client := logger.NewUltronEx(logger.UltronExConfig{Transport: transport})
+	assert.Error(client.Alert(context.Background(), &logger.UltronExMsg{Title: "booking failed"}))
+	assert.Empty(transport.sent())
+}
+
+func Test_UltronEx_Alert_Throttles(t *testing.T) {
+	assert := assert.New(t)
+
+	transport := &recordingTransport{}
+	client := logger.NewUltronEx(logger.UltronExConfig{
+		Throttle:  logger.UltronExThrottle{Window: time.Hour, Max: 2},
+		Throttles: map[string]logger.UltronExThrottle{"on-call": {}},
+		Transport: transport,
+	})
+
+	ctx := context.Background()
+	for _, msg := range []logger.UltronExMsg{
+		{Channel: "bookings", Title: "a"},
+		{Channel: "bookings", Title: "b"},
+		{Channel: "bookings", Title: "c", Severity: logger.SeverityWarning},
+		{Channel: "bookings", Title: "d", Severity: logger.SeverityError},
+		{Channel: "bookings", Title: "c"},
+		{Channel: "on-call", Title: "e"},
+		{Channel: "on-call", Title: "f"},
+		{Channel: "on-call", Title: "g"},
+	} {
+		assert.NoError(client.Alert(ctx, &msg))
+	}
+
+	var titles []string
+	for _, msg := range transport.sent() {
+		titles = append(titles, msg.Title)
+	}
+	assert.Equal([]string{"a", "b", "e", "f", "g"}, titles)
+
+	assert.NoError(client.Flush(ctx))
+	sent := transport.sent()
+	if assert.Len(sent, 6) {
+		digest := sent[5]
+		assert.Equal("bookings", digest.Channel)
+		assert.Equal(logger.SeverityError, digest.Severity)
+		assert.Equal("3 alerts were throttled", digest.Text)
+		assert.Equal([]logger.UltronExAttachment{
+			{Title: "c", Text: "2 times"},
+			{Title: "d", Text: "1 times"},
+		}, digest.Attachments)
+	}
+
+	// the window is ended by Flush
+	assert.NoError(client.Alert(ctx, &logger.UltronExMsg{Channel: "bookings", Title: "h"}))
+	assert.Len(transport.sent(), 7)
+}
+
+func Test_UltronEx_Alert_SendsDigestAtTheEndOfTheWindow(t *testing.T) {
+	assert := assert.New(t)
+
+	transport := &recordingTransport{}
+	client := logger.NewUltronEx(logger.UltronExConfig{
+		Throttle:  logger.UltronExThrottle{Window: 50 * time.Millisecond},
+		Transport: transport,
+	})
+
+	ctx := context.Background()
+	assert.NoError(client.Alert(ctx, &logger.UltronExMsg{Channel: "bookings", Title: "a"}))
+	assert.NoError(client.Alert(ctx, &logger.UltronExMsg{Channel: "bookings", Title: "a"}))
+
+	assert.Eventually(func() bool { return len(transport.sent()) == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal("1 alerts were throttled", transport.sent()[1].Text)
+}
+
+func Test_UltronExWebhookTransport(t *testing.T) {
+	assert := assert.New(t)
+
+	var (
+		body   map[string]any
+		header http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		assert.NoError(json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := logger.NewUltronEx(logger.UltronExConfig{
+		DefaultChannel: "bookings",
+		Transport: logger.UltronExWebhookTransport{
+			URL:     server.URL,
+			Headers: map[string]string{"Authorization": "Bearer token"},
+		},
+	})
+	err := client.Alert(context.Background(), &logger.UltronExMsg{
+		Text:     "Booking failed",
+		Title:    "POST /bookings",
+		Payload:  "```",
+		Severity: logger.SeverityWarning,
+	})
+	assert.NoError(err)
+
+	assert.Equal("application/json", header.Get("Content-Type"))
+	assert.Equal("Bearer token", header.Get("Authorization"))
+	assert.Equal(map[string]any{
+		"channel":  "bookings",
+		"severity": "warning",
+		"text":     "Booking failed",
+		"attachments": []any{
+			map[string]any{"title": "POST /bookings", "text": "```\n``\u200b`\n```", "color": "warning"},
+		},
+	}, body)
+}
+
+func Test_UltronExWebhookTransport_Format(t *testing.T) {
+	assert := assert.New(t)
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	transport := logger.UltronExWebhookTransport{URL: server.URL}
+	err := transport.Send(context.Background(), &logger.UltronExMsg{
+		Channel:     "bookings",
+		Text:        "Booking <failed> & retried",
+		Title:       "POST /bookings",
+		Payload:     "{\"note\":\"`a` ```\n@channel``````\"}",
+		Severity:    logger.SeverityError,
+		RequestType: "partner_search",
+		Fields:      []logger.UltronExField{{Title: "Partner", Value: "<conferma>", Short: true}},
+		Attachments: []logger.UltronExAttachment{{Title: "Response", Text: "500", Payload: "`error`"}},
+	})
+	assert.NoError(err)
+
+	assert.Equal(map[string]any{
+		"channel":      "bookings",
+		"severity":     "error",
+		"request_type": "partner_search",
+		"text":         "Booking &lt;failed&gt; &amp; retried",
+		"attachments": []any{
+			map[string]any{
+				"title": "POST /bookings",
+				"text":  "```\n{\"note\":\"`a` ``\u200b`\n@channel``\u200b``\u200b`\u200b`\"}\n```",
+				"color": "danger",
+				"fields": []any{
+					map[string]any{"title": "Partner", "value": "&lt;conferma&gt;", "short": true},
+				},
+			},
+			map[string]any{
+				"title": "Response",
+				"text":  "500\n```\n`error`\n```",
+				"color": "danger",
+			},
+		},
+	}, body)
+
+	text := body["attachments"].([]any)[0].(map[string]any)["text"].(string)
+	assert.Equal(2, strings.Count(text, "```"), "the payload cannot close the code block")
+}
+
+func Test_UltronExWebhookTransport_ErrorStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	transport := logger.UltronExWebhookTransport{URL: server.URL}
+	err := transport.Send(context.Background(), &logger.UltronExMsg{Channel: "bookings"})
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "502")
+	}
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"context"
+	goErrors "errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/wego/pkg/common"
+	"github.com/wego/pkg/env"
+	"github.com/wego/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// the keys of the fields of the application logs
+const (
+	appKeyService   = "service"
+	appKeyVersion   = "version"
+	appKeyEnv       = "env"
+	appKeyBasics    = "basics"
+	appKeyRequestID = "request_id"
+)
+
+var (
+	appLogger atomic.Pointer[zap.Logger]
+	appLevel  = zap.NewAtomicLevel()
+)
+
+/*
+FromContext returns the application logger, set up by Init, with the fields of the context:
+  - the service, the version & the environment of env
+  - the basics of common.GetBasics
+  - the request ID
+  - the trace & span IDs of the Datadog or OpenTelemetry span of the context, so the logs are correlated with the
+    traces
+
+The *errors.Error logged with zap.Error are logged with their kind & their stack of operations:
+
+	logger.FromContext(ctx).Error("cannot create booking", zap.Error(err))
+
+The global zap logger is used when Init is not called.
+*/
+func FromContext(ctx context.Context) *zap.Logger {
+	l := appLogger.Load()
+	if l == nil {
+		l = zap.L()
+	}
+	if ctx == nil {
+		return l
+	}
+
+	var fields []zap.Field
+	if basics := common.GetBasics(ctx); len(basics) > 0 {
+		fields = append(fields, zap.Any(appKeyBasics, basics))
+	}
+	if reqID, ok := errors.RequestID(ctx); ok && reqID != "" {
+		fields = append(fields, zap.String(appKeyRequestID, reqID))
+	}
+	fields = append(fields, traceOf(ctx).fields()...)
+
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
+
+// SetLevel sets the level of the application logger
+func SetLevel(level zapcore.Level) {
+	appLevel.SetLevel(level)
+}
+
+/*
+LevelHandler returns a http.Handler to get & change the level of the application logger at runtime:
+
+	GET  -> {"level":"info"}
+	PUT  -> {"level":"debug"}, or level=debug as a form
+*/
+func LevelHandler() http.Handler {
+	return appLevel
+}
+
+func newAppLogger(sink Sink) (*zap.Logger, error) {
+	if sink.Type == "" && sink.WriteSyncer == nil && sink.Path == "" {
+		sink.Type = SinkStdout
+	}
+	ws, err := sink.writeSyncer(appFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), ws, appLevel)
+	return zap.New(errorCore{core}, zap.AddCaller()).With(
+		zap.String(appKeyService, env.ServiceName()),
+		zap.String(appKeyVersion, env.Version()),
+		zap.String(appKeyEnv, env.Env()),
+	), nil
+}
+
+// errorCore logs the *errors.Error fields with their kind & their stack of operations
+type errorCore struct {
+	zapcore.Core
+}
+
+func (c errorCore) With(fields []zapcore.Field) zapcore.Core {
+	return errorCore{c.Core.With(errorFields(fields))}
+}
+
+func (c errorCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c errorCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, errorFields(fields))
+}
+
+// errorFields replaces the error fields of *errors.Error with errorObject fields, the fields are copied when any of
+// them is replaced
+func errorFields(fields []zapcore.Field) []zapcore.Field {
+	res := fields
+	copied := false
+	for i, field := range fields {
+		if field.Type != zapcore.ErrorType {
+			continue
+		}
+
+		err, _ := field.Interface.(error)
+		var e *errors.Error
+		if !goErrors.As(err, &e) {
+			continue
+		}
+
+		if !copied {
+			res = make([]zapcore.Field, len(fields))
+			copy(res, fields)
+			copied = true
+		}
+		res[i] = zap.Object(field.Key, errorObject{err: err})
+	}
+	return res
+}
+
+// errorObject is an error logged with the kind & the stack of operations of the *errors.Error in its chain
+type errorObject struct {
+	err error
+}
+
+func (o errorObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("message", o.err.Error())
+	enc.AddInt("kind", errors.Code(o.err))
+	return enc.AddArray("ops", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, op := range errors.Ops(o.err) {
+			arr.AppendString(string(op))
+		}
+		return nil
+	}))
+}
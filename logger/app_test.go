@@ -0,0 +1,162 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	goErrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/common"
+	"github.com/wego/pkg/errors"
+	"github.com/wego/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+func initAppLogger(t *testing.T, level zapcore.Level) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	dir := t.TempDir()
+	err := logger.Init(logger.Config{
+		UltronEx:       logger.Sink{Path: filepath.Join(dir, "ultronex.log")},
+		PartnerRequest: logger.Sink{Path: filepath.Join(dir, "partner_requests.log")},
+		Request:        logger.Sink{Path: filepath.Join(dir, "requests.log")},
+		PromoCodeEvent: logger.Sink{Path: filepath.Join(dir, "promocode_events.log")},
+		App:            logger.Sink{WriteSyncer: zapcore.AddSync(&buf)},
+		AppLevel:       level,
+	})
+	assert.NoError(t, err)
+	return &buf
+}
+
+func decodeAppLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var logs []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var log map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &log))
+		logs = append(logs, log)
+	}
+	return logs
+}
+
+func Test_FromContext(t *testing.T) {
+	assert := assert.New(t)
+
+	viper.Set("service_name", "bookings")
+	viper.Set("version", "1.2.3")
+	buf := initAppLogger(t, zapcore.InfoLevel)
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "booking.create")
+	defer span.Finish()
+
+	ctx = common.SetBasics(ctx, common.Basics{"booking_id": "B1"})
+	ctx = errors.WithRequestID(ctx, "req-1")
+
+	logger.FromContext(ctx).Info("booking created", zap.Int("amount", 10))
+	logger.Sync()
+
+	logs := decodeAppLogs(t, buf)
+	if assert.Len(logs, 1) {
+		log := logs[0]
+		assert.Equal("info", log["level"])
+		assert.Equal("booking created", log["msg"])
+		assert.EqualValues(10, log["amount"])
+		assert.Equal("bookings", log["service"])
+		assert.Equal("1.2.3", log["version"])
+		assert.Equal(map[string]any{"booking_id": "B1"}, log["basics"])
+		assert.Equal("req-1", log["request_id"])
+		assert.Equal(strconv.FormatUint(span.Context().TraceID(), 10), log["dd.trace_id"])
+		assert.Equal(strconv.FormatUint(span.Context().SpanID(), 10), log["dd.span_id"])
+		assert.Contains(log["caller"], "app_test.go")
+	}
+}
+
+func Test_FromContext_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initAppLogger(t, zapcore.InfoLevel)
+	err := errors.New(errors.Op("booking.Create"), errors.New(errors.Op("repo.Get"), errors.NotFound, "booking not found"))
+
+	l := logger.FromContext(context.Background()).With(zap.Error(err))
+	other := goErrors.New("other error")
+	l.Error("cannot create booking", zap.NamedError("cause", err), zap.NamedError("other", other))
+	logger.Sync()
+
+	logs := decodeAppLogs(t, buf)
+	if assert.Len(logs, 1) {
+		expected := map[string]any{
+			"message": "booking not found",
+			"kind":    float64(404),
+			"ops":     []any{"booking.Create", "repo.Get"},
+		}
+		assert.Equal(expected, logs[0]["error"])
+		assert.Equal(expected, logs[0]["cause"])
+		assert.Equal("other error", logs[0]["other"])
+	}
+}
+
+func Test_FromContext_WrappedErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initAppLogger(t, zapcore.InfoLevel)
+	repoErr := errors.New(errors.Op("repo.Get"), errors.NotFound, "booking not found")
+	err := fmt.Errorf("handler: %w", errors.New(errors.Op("booking.Create"), fmt.Errorf("service: %w", repoErr)))
+
+	logger.FromContext(context.Background()).Error("cannot create booking", zap.Error(err))
+	logger.Sync()
+
+	logs := decodeAppLogs(t, buf)
+	if assert.Len(logs, 1) {
+		assert.Equal(map[string]any{
+			"message": "handler: service: booking not found",
+			"kind":    float64(404),
+			"ops":     []any{"booking.Create", "repo.Get"},
+		}, logs[0]["error"])
+	}
+}
+
+func Test_LevelHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initAppLogger(t, zapcore.InfoLevel)
+	handler := logger.LevelHandler()
+
+	logger.FromContext(context.Background()).Debug("hidden")
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"debug"}`)))
+	assert.Equal(http.StatusOK, res.Code)
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+	assert.JSONEq(`{"level":"debug"}`, res.Body.String())
+
+	logger.FromContext(context.Background()).Debug("shown")
+	logger.SetLevel(zapcore.WarnLevel)
+	logger.FromContext(context.Background()).Info("hidden")
+	logger.Sync()
+
+	logs := decodeAppLogs(t, buf)
+	if assert.Len(logs, 1) {
+		assert.Equal("shown", logs[0]["msg"])
+	}
+}
@@ -0,0 +1,276 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-go/statsd"
+	"github.com/wego/pkg/common"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// OverflowPolicy is what the asynchronous mode does with a log when its queue is full
+type OverflowPolicy string
+
+// overflow policies
+const (
+	// OverflowDropNewest drops the log being queued
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest drops the oldest log in the queue to queue the new one
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowBlock blocks the caller until there is room in the queue
+	OverflowBlock OverflowPolicy = "block"
+)
+
+const (
+	defaultQueueSize  = 1024
+	defaultBatchSize  = 64
+	defaultDropMetric = "logger.dropped"
+)
+
+// AsyncConfig configures the asynchronous mode of LogRequest & LogPartnerRequest
+type AsyncConfig struct {
+	// QueueSize is the number of logs waiting to be written, 1024 is used when it's 0
+	QueueSize int
+	// BatchSize is the max number of logs written to the sink at once, 64 is used when it's 0
+	BatchSize int
+	// Overflow is the policy when the queue is full, OverflowDropNewest is used when it's empty
+	Overflow OverflowPolicy
+	// Metric is the name of the drop counter, "logger.dropped" is used when it's empty
+	Metric string
+}
+
+// asyncEntry is a queued log
+type asyncEntry struct {
+	logType logType
+	req     *Request
+}
+
+// asyncLogger writes the queued logs in batches from a single goroutine
+type asyncLogger struct {
+	conf    AsyncConfig
+	statsD  *statsd.Client
+	queue   chan asyncEntry
+	flushes chan chan struct{}
+	done    chan struct{}
+	dropped atomic.Int64
+	// mu guards the queue against being closed while a log is queued
+	mu     sync.RWMutex
+	closed bool
+	// loggers & writers are only used by the goroutine
+	loggers map[logType]*zap.Logger
+	writers []*batchWriter
+}
+
+var asyncLog atomic.Pointer[asyncLogger]
+
+/*
+EnableAsync makes LogRequest & LogPartnerRequest queue the logs, which are marshaled & written in batches by a
+background goroutine, so the request path does not wait for them. The drops caused by the overflow policy are counted
+with the statsd client of the context, see common.SetStatsD, tagged by the log type & the policy.
+
+Init disables the asynchronous mode, so it's enabled after Init. The logged requests are copied when they are queued,
+but their basics & headers must not be modified after they are logged. Sync writes all the queued logs, so it should
+be called on shutdown.
+*/
+func EnableAsync(ctx context.Context, conf AsyncConfig) {
+	if conf.QueueSize <= 0 {
+		conf.QueueSize = defaultQueueSize
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = defaultBatchSize
+	}
+	if conf.Overflow == "" {
+		conf.Overflow = OverflowDropNewest
+	}
+	if conf.Metric == "" {
+		conf.Metric = defaultDropMetric
+	}
+
+	a := &asyncLogger{
+		conf:    conf,
+		statsD:  common.GetStatsD(ctx),
+		queue:   make(chan asyncEntry, conf.QueueSize),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+		loggers: make(map[logType]*zap.Logger, 2),
+	}
+	for _, lt := range []logType{logTypeRequest, logTypePartnerRequest} {
+		if ws := writeSyncers[lt]; ws != nil {
+			w := &batchWriter{ws: ws}
+			a.writers = append(a.writers, w)
+			a.loggers[lt] = newLogger(w)
+		}
+	}
+
+	go a.run()
+	if previous := asyncLog.Swap(a); previous != nil {
+		previous.stop()
+	}
+}
+
+// DisableAsync writes the queued logs & makes LogRequest & LogPartnerRequest write the logs synchronously again
+func DisableAsync() {
+	if a := asyncLog.Swap(nil); a != nil {
+		a.stop()
+	}
+}
+
+// Dropped returns the number of logs dropped by the overflow policy since the asynchronous mode is enabled
+func Dropped() int64 {
+	if a := asyncLog.Load(); a != nil {
+		return a.dropped.Load()
+	}
+	return 0
+}
+
+// enqueue queues the log when the asynchronous mode is enabled, it reports whether the log is handled
+func enqueue(lt logType, req *Request) bool {
+	a := asyncLog.Load()
+	if a == nil {
+		return false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return false
+	}
+
+	// the request is copied, so the caller can reuse it
+	copied := *req
+	entry := asyncEntry{logType: lt, req: &copied}
+	switch a.conf.Overflow {
+	case OverflowBlock:
+		a.queue <- entry
+	case OverflowDropOldest:
+		for {
+			select {
+			case a.queue <- entry:
+				return true
+			default:
+			}
+
+			select {
+			case oldest := <-a.queue:
+				a.drop(oldest.logType)
+			default:
+			}
+		}
+	default:
+		select {
+		case a.queue <- entry:
+		default:
+			a.drop(lt)
+		}
+	}
+	return true
+}
+
+func (a *asyncLogger) drop(lt logType) {
+	a.dropped.Add(1)
+	if a.statsD != nil {
+		_ = a.statsD.Incr(a.conf.Metric, []string{"log_type:" + string(lt), "policy:" + string(a.conf.Overflow)}, 1)
+	}
+}
+
+func (a *asyncLogger) run() {
+	defer close(a.done)
+
+	batch := make([]asyncEntry, 0, a.conf.BatchSize)
+	for {
+		select {
+		case entry, ok := <-a.queue:
+			if !ok {
+				return
+			}
+			a.write(a.fill(append(batch[:0], entry)))
+		case flushed := <-a.flushes:
+			for batch = a.fill(batch[:0]); len(batch) > 0; batch = a.fill(batch[:0]) {
+				a.write(batch)
+			}
+			close(flushed)
+		}
+	}
+}
+
+// fill adds the queued logs to the batch, without waiting for more logs
+func (a *asyncLogger) fill(batch []asyncEntry) []asyncEntry {
+	for len(batch) < a.conf.BatchSize {
+		select {
+		case entry, ok := <-a.queue:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (a *asyncLogger) write(batch []asyncEntry) {
+	for _, entry := range batch {
+		if logger := a.loggers[entry.logType]; logger != nil {
+			logger.Info("", detectRequest(entry.req, entry.logType).fields()...)
+		}
+	}
+	for _, w := range a.writers {
+		_ = w.flush()
+	}
+}
+
+// flush waits until the queued logs are written
+func (a *asyncLogger) flush() {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return
+	}
+
+	flushed := make(chan struct{})
+	a.flushes <- flushed
+	<-flushed
+}
+
+// stop writes the queued logs & stops the goroutine
+func (a *asyncLogger) stop() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+	<-a.done
+}
+
+// batchWriter buffers the logs of a batch, which are written to the sink at once
+type batchWriter struct {
+	ws  zapcore.WriteSyncer
+	buf bytes.Buffer
+}
+
+func (w *batchWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *batchWriter) Sync() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return w.ws.Sync()
+}
+
+func (w *batchWriter) flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	_, err := w.ws.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
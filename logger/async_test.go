@@ -0,0 +1,204 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// blockingWriter blocks the writes until it's released
+type blockingWriter struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	writes   int
+	started  chan struct{}
+	released chan struct{}
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{started: make(chan struct{}, 1), released: make(chan struct{})}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- struct{}{}:
+	default:
+	}
+	<-w.released
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Sync() error {
+	return nil
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func initAsyncRequestLog(t *testing.T, ws zapcore.WriteSyncer, conf logger.AsyncConfig) {
+	t.Helper()
+
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	err := logger.Init(logger.Config{
+		UltronEx:       stdout,
+		PartnerRequest: stdout,
+		Request:        logger.Sink{WriteSyncer: ws},
+		PromoCodeEvent: stdout,
+	})
+	assert.NoError(t, err)
+	logger.EnableAsync(context.Background(), conf)
+	t.Cleanup(logger.DisableAsync)
+}
+
+func loggedURLs(output string) []string {
+	var urls []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if _, url, found := strings.Cut(line, `"url":"`); found {
+			urls = append(urls, url[:strings.IndexByte(url, '"')])
+		}
+	}
+	return urls
+}
+
+func Test_EnableAsync_SyncWritesQueuedLogs(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	initAsyncRequestLog(t, zapcore.AddSync(&buf), logger.AsyncConfig{BatchSize: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.LogRequest(&logger.Request{Type: requestType, URL: "/bookings"})
+		}()
+	}
+	wg.Wait()
+	logger.Sync()
+
+	assert.Len(loggedURLs(buf.String()), 10)
+	assert.Zero(logger.Dropped())
+}
+
+func Test_EnableAsync_CopiesRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	initAsyncRequestLog(t, zapcore.AddSync(&buf), logger.AsyncConfig{})
+
+	req := &logger.Request{Type: requestType, URL: "/1"}
+	logger.LogRequest(req)
+	req.URL = "/2"
+	logger.LogRequest(req)
+	logger.Sync()
+
+	assert.Equal([]string{"/1", "/2"}, loggedURLs(buf.String()))
+}
+
+func Test_EnableAsync_Overflow(t *testing.T) {
+	tests := map[string]struct {
+		policy   logger.OverflowPolicy
+		expected []string
+	}{
+		"drop newest": {
+			policy:   logger.OverflowDropNewest,
+			expected: []string{"/0", "/1", "/2"},
+		},
+		"drop oldest": {
+			policy:   logger.OverflowDropOldest,
+			expected: []string{"/0", "/3", "/4"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			w := newBlockingWriter()
+			initAsyncRequestLog(t, w, logger.AsyncConfig{QueueSize: 2, BatchSize: 1, Overflow: test.policy})
+
+			// the first log is being written, so the queue is full after the next 2 logs
+			logger.LogRequest(&logger.Request{Type: requestType, URL: "/0"})
+			<-w.started
+			for _, url := range []string{"/1", "/2", "/3", "/4"} {
+				logger.LogRequest(&logger.Request{Type: requestType, URL: url})
+			}
+			assert.EqualValues(2, logger.Dropped())
+
+			close(w.released)
+			logger.Sync()
+			assert.Equal(test.expected, loggedURLs(w.String()))
+		})
+	}
+}
+
+func Test_EnableAsync_OverflowBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	w := newBlockingWriter()
+	initAsyncRequestLog(t, w, logger.AsyncConfig{QueueSize: 1, BatchSize: 1, Overflow: logger.OverflowBlock})
+
+	logger.LogRequest(&logger.Request{Type: requestType, URL: "/0"})
+	<-w.started
+	logger.LogRequest(&logger.Request{Type: requestType, URL: "/1"})
+
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		logger.LogRequest(&logger.Request{Type: requestType, URL: "/2"})
+	}()
+
+	close(w.released)
+	<-logged
+	logger.Sync()
+
+	assert.Equal([]string{"/0", "/1", "/2"}, loggedURLs(w.String()))
+	assert.Zero(logger.Dropped())
+}
+
+func Test_EnableAsync_WritesBatches(t *testing.T) {
+	assert := assert.New(t)
+
+	w := newBlockingWriter()
+	initAsyncRequestLog(t, w, logger.AsyncConfig{QueueSize: 10, BatchSize: 5})
+
+	logger.LogRequest(&logger.Request{Type: requestType, URL: "/0"})
+	<-w.started
+	for i := 0; i < 5; i++ {
+		logger.LogRequest(&logger.Request{Type: requestType, URL: "/bookings"})
+	}
+	close(w.released)
+	logger.Sync()
+
+	assert.Len(loggedURLs(w.String()), 6)
+	w.mu.Lock()
+	assert.Equal(2, w.writes)
+	w.mu.Unlock()
+}
+
+func Test_DisableAsync_WritesQueuedLogs(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	initAsyncRequestLog(t, zapcore.AddSync(&buf), logger.AsyncConfig{})
+
+	logger.LogRequest(&logger.Request{Type: requestType, URL: "/0"})
+	logger.DisableAsync()
+	logger.LogRequest(&logger.Request{Type: requestType, URL: "/1"})
+
+	assert.Equal([]string{"/0", "/1"}, loggedURLs(buf.String()))
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	httpHeader "github.com/wego/pkg/http/header"
+)
+
+const (
+	truncatedMarker = "...[truncated]"
+	// nonJSONMarker is recorded instead of the non-JSON bodies having JSON rules to apply
+	nonJSONMarker = "...[non-JSON body omitted]"
+	// unsupportedMarker is recorded instead of the bodies having a policy to apply, which does not support their format
+	unsupportedMarker = "...[unsupported body omitted]"
+)
+
+// cappedBuffer is a buffer keeping the first max bytes written into it, it's safe for concurrent use
+type cappedBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	remaining := b.max - b.buf.Len()
+	if len(p) > remaining {
+		b.truncated = true
+	}
+	if remaining > 0 {
+		_, _ = b.buf.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// bodyRules are the rules applied on a recorded body
+type bodyRules struct {
+	maskChar    string
+	replacement string
+	masks       []MaskData
+	redacts     [][]string
+	policy      *MaskingPolicy
+}
+
+func (r bodyRules) empty() bool {
+	return len(r.masks) == 0 && len(r.redacts) == 0 && r.policy == nil
+}
+
+/*
+record returns the body with the rules applied:
+  - the masks & the redactions are applied on JSON bodies
+  - the policy is applied on the bodies of the formats it supports, see MaskingPolicy.Apply
+
+A body which the rules cannot be applied on is returned as a marker instead, i.e. a truncated body or a body of another
+format, while a body without rules is returned as is, with the truncated marker when it's truncated.
+A body without content type is sniffed as JSON.
+*/
+func (b *cappedBuffer) record(contentType string, rules bodyRules) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	body := b.buf.String()
+	switch {
+	case body == "":
+		return body
+	case b.truncated && !rules.empty():
+		return truncatedMarker
+	case b.truncated:
+		return body + truncatedMarker
+	}
+
+	if contentType == "" && json.Valid([]byte(body)) {
+		contentType = httpHeader.ApplicationJSON
+	}
+	format := formatOf(contentType)
+
+	if len(rules.masks) > 0 || len(rules.redacts) > 0 {
+		if format != formatJSON {
+			return nonJSONMarker
+		}
+		if len(rules.masks) > 0 {
+			body = MaskJSON(body, rules.maskChar, rules.masks)
+		}
+		if len(rules.redacts) > 0 {
+			body = RedactJSON(body, rules.replacement, rules.redacts)
+		}
+	}
+
+	if rules.policy != nil {
+		if format == formatOther {
+			return unsupportedMarker
+		}
+		body = rules.policy.Apply(contentType, body)
+	}
+	return body
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // RequestType ...
@@ -15,6 +16,8 @@ const (
 	partnerRequestsFileName = "partner_requests.{{env}}.log"
 	requestsFileName        = "requests.{{env}}.log"
 	promoCodeEventsFileName = "promocode_events.log"
+	appFileName             = "app.{{env}}.log"
+	eventsFileName          = "{{name}}_events.{{env}}.log"
 	defaultReplacement      = "[Filtered by Wego]"
 	defaultMaskChar         = "*"
 	arrayKey                = "[]"
@@ -26,13 +29,9 @@ const (
 
 	contextKeyRequest     contextKey = "request"
 	contextKeyRequestType contextKey = "requestType"
-
-	sensitiveHeaderAuthorization = "authorization"
 )
 
 var (
-	loggers          map[logType]*zap.Logger
-	sensitiveHeaders = map[string]bool{
-		sensitiveHeaderAuthorization: true,
-	}
+	loggers      map[logType]*zap.Logger
+	writeSyncers map[logType]zapcore.WriteSyncer
 )
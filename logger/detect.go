@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-go/statsd"
+	"github.com/wego/pkg/common"
+)
+
+// PIIType is the type of personal or payment data found by the detector
+type PIIType string
+
+// PII types
+const (
+	PIITypePAN   PIIType = "pan"
+	PIITypeCVV   PIIType = "cvv"
+	PIITypeEmail PIIType = "email"
+	PIITypePhone PIIType = "phone"
+)
+
+const (
+	defaultDetectionMetric = "logger.pii_detected"
+	minPANLength           = 13
+)
+
+var (
+	// panRegex matches 13 to 19 digits, optionally grouped by spaces or dashes
+	panRegex = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	// cvvRegex matches a CVV-like field, e.g. "cvv":"123" or cvc=123
+	cvvRegex   = regexp.MustCompile(`(?i)((?:cvv2?|cvc2?|csc|security_?code)["']?\s*[:=]\s*["']?)(\d{3,4})\b`)
+	emailRegex = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// phoneRegex matches international phone numbers, which start with +
+	phoneRegex = regexp.MustCompile(`\+\d{1,3}[ -]?\d(?:[ -]?\d){6,13}\b`)
+)
+
+// DetectorConfig configures the detection of PII in the logged bodies & payloads
+type DetectorConfig struct {
+	// MaskChar is the char to mask the detected values with, the default mask char is used when it's empty
+	MaskChar string
+	// StatsD counts the detections by type & log type, nothing is sent when it's nil
+	StatsD statsd.ClientInterface
+	// Metric is the name of the detection counter, "logger.pii_detected" is used when it's empty
+	Metric string
+}
+
+// detector masks the PII found in the logs & counts the detections
+type detector struct {
+	conf   DetectorConfig
+	mu     sync.Mutex
+	counts map[PIIType]int64
+}
+
+var piiDetector atomic.Pointer[detector]
+
+/*
+EnableDetection enables the detection of PII in the bodies of the requests & the payloads of the UltronEx messages
+before they are logged, which masks:
+  - Luhn-valid card numbers (PANs), showing the first 6 & last 4 digits
+  - CVV-like fields, e.g. "cvv":"123", when there is a PAN in the same body
+  - emails, showing the first 2 & last 4 chars
+  - international phone numbers starting with +, showing the first 3 & last 2 chars
+
+It's a safety net for the values the masking rules miss, every detection is counted so the leaks can be alerted on.
+*/
+func EnableDetection(conf DetectorConfig) {
+	if conf.Metric == "" {
+		conf.Metric = defaultDetectionMetric
+	}
+	piiDetector.Store(&detector{conf: conf, counts: map[PIIType]int64{}})
+}
+
+// DisableDetection disables the detection of PII
+func DisableDetection() {
+	piiDetector.Store(nil)
+}
+
+// Detections returns the number of detections of each PII type since the detection is enabled
+func Detections() map[PIIType]int64 {
+	d := piiDetector.Load()
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	res := make(map[PIIType]int64, len(d.counts))
+	for piiType, count := range d.counts {
+		res[piiType] = count
+	}
+	return res
+}
+
+// detectRequest returns a copy of the request with the PII in its bodies masked, or the request itself when the
+// detection is disabled
+func detectRequest(req *Request, lt logType) *Request {
+	d := piiDetector.Load()
+	if d == nil {
+		return req
+	}
+
+	res := *req
+	res.RequestBody = d.mask(req.RequestBody, lt)
+	res.ResponseBody = d.mask(req.ResponseBody, lt)
+	return &res
+}
+
+// detectUltronEx returns a copy of the message with the PII in its payloads masked, or the message itself when the
+// detection is disabled
+func detectUltronEx(msg *UltronExMsg) *UltronExMsg {
+	d := piiDetector.Load()
+	if d == nil {
+		return msg
+	}
+
+	res := *msg
+	res.Payload = d.mask(msg.Payload, logTypeUltronex)
+	if len(msg.Attachments) > 0 {
+		res.Attachments = make([]UltronExAttachment, len(msg.Attachments))
+		for i, a := range msg.Attachments {
+			a.Payload = d.mask(a.Payload, logTypeUltronex)
+			res.Attachments[i] = a
+		}
+	}
+	return &res
+}
+
+func (d *detector) mask(s string, lt logType) string {
+	if s == "" {
+		return s
+	}
+
+	maskChar := maskCharOrDefault(d.conf.MaskChar)
+	panFound := false
+	s = panRegex.ReplaceAllStringFunc(s, func(match string) string {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(match)
+		// card numbers start with 2 to 6, e.g. 4 for Visa & 5 for Mastercard
+		if len(digits) < minPANLength || digits[0] < '2' || digits[0] > '6' || !common.ValidateCardNumber(digits) {
+			return match
+		}
+
+		panFound = true
+		d.count(PIITypePAN, lt)
+		return getMaskedValue(maskChar, digits, MaskData{FirstCharsToShow: 6, LastCharsToShow: 4, KeepSameLength: true})
+	})
+
+	if panFound {
+		s = cvvRegex.ReplaceAllStringFunc(s, func(match string) string {
+			groups := cvvRegex.FindStringSubmatch(match)
+			d.count(PIITypeCVV, lt)
+			return groups[1] + strings.Repeat(maskChar, len(groups[2]))
+		})
+	}
+
+	s = emailRegex.ReplaceAllStringFunc(s, func(match string) string {
+		if !willMask(match, MaskRestrictionTypeEmail) {
+			return match
+		}
+
+		d.count(PIITypeEmail, lt)
+		return getMaskedValue(maskChar, match, MaskData{
+			FirstCharsToShow: 2,
+			LastCharsToShow:  4,
+			CharsToIgnore:    []rune{'@'},
+			KeepSameLength:   true,
+		})
+	})
+
+	return phoneRegex.ReplaceAllStringFunc(s, func(match string) string {
+		d.count(PIITypePhone, lt)
+		return getMaskedValue(maskChar, match, MaskData{FirstCharsToShow: 3, LastCharsToShow: 2, KeepSameLength: true})
+	})
+}
+
+func (d *detector) count(piiType PIIType, lt logType) {
+	d.mu.Lock()
+	d.counts[piiType]++
+	d.mu.Unlock()
+
+	if d.conf.StatsD != nil {
+		_ = d.conf.StatsD.Incr(d.conf.Metric, []string{"pii_type:" + string(piiType), "log_type:" + string(lt)}, 1)
+	}
+}
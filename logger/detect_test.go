@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_detector_mask(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+		counts   map[PIIType]int64
+	}{
+		"PAN & CVV": {
+			input:    `{"note":"card 4111 1111 1111 1111","cvv":"123"}`,
+			expected: `{"note":"card 411111******1111","cvv":"***"}`,
+			counts:   map[PIIType]int64{PIITypePAN: 1, PIITypeCVV: 1},
+		},
+		"CVV-like field without PAN": {
+			input:    `{"cvv":"123"}`,
+			expected: `{"cvv":"123"}`,
+			counts:   map[PIIType]int64{},
+		},
+		"not Luhn-valid": {
+			input:    `{"booking_id":"4111111111111112"}`,
+			expected: `{"booking_id":"4111111111111112"}`,
+			counts:   map[PIIType]int64{},
+		},
+		"email": {
+			input:    `<Contact>someone@wego.com</Contact>`,
+			expected: `<Contact>so*****@****.com</Contact>`,
+			counts:   map[PIIType]int64{PIITypeEmail: 1},
+		},
+		"phone": {
+			input:    `phone=+65 91234567&id=91234567`,
+			expected: `phone=+65*******67&id=91234567`,
+			counts:   map[PIIType]int64{PIITypePhone: 1},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			EnableDetection(DetectorConfig{})
+			defer DisableDetection()
+
+			d := piiDetector.Load()
+			assert.Equal(t, tc.expected, d.mask(tc.input, logTypePartnerRequest))
+			assert.Equal(t, tc.counts, Detections())
+		})
+	}
+}
+
+func Test_detectRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	req := &Request{RequestBody: `{"email":"someone@wego.com"}`, ResponseBody: `{"pan":"5555555555554444"}`}
+	assert.Same(req, detectRequest(req, logTypeRequest))
+	assert.Nil(Detections())
+
+	EnableDetection(DetectorConfig{MaskChar: "#"})
+	defer DisableDetection()
+
+	detected := detectRequest(req, logTypeRequest)
+	assert.Equal(`{"email":"so#####@####.com"}`, detected.RequestBody)
+	assert.Equal(`{"pan":"555555######4444"}`, detected.ResponseBody)
+	// the original request is not modified
+	assert.Equal(`{"email":"someone@wego.com"}`, req.RequestBody)
+
+	msg := detectUltronEx(&UltronExMsg{Payload: "contact someone@wego.com"})
+	assert.Equal("contact so#####@####.com", msg.Payload)
+	assert.Equal(map[PIIType]int64{PIITypeEmail: 2, PIITypePAN: 1}, Detections())
+}
@@ -0,0 +1,247 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/wego/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// the keys of the metadata of the events
+const (
+	eventKeyName    = "event"
+	eventKeyVersion = "schema_version"
+	eventTag        = "event"
+)
+
+var eventNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var (
+	eventsMu sync.Mutex
+	// eventSchemas are the types & versions of the registered event logs by name
+	eventSchemas = map[string]eventSchema{}
+	// eventSinks & eventLoggers are set by Init, the loggers are created on the first event of their name
+	eventSinks   map[string]Sink
+	eventLoggers map[string]*zap.Logger
+)
+
+type eventSchema struct {
+	typ     reflect.Type
+	version int
+}
+
+// eventField is a field of an event struct
+type eventField struct {
+	index    []int
+	name     string
+	required bool
+	redact   bool
+}
+
+/*
+EventLog is a named stream of events of type T, which are written to the sink of their name in Config.Events. Each
+event is logged with the name & the schema version of the stream, so the consumers can follow the changes of T:
+
+	{"event":"promo_code_redeemed","schema_version":2,"code":"WEGO","user_id":"[Filtered by Wego]"}
+
+The fields of T are named by their event tag, or by their json tag when they have no event tag, with the options:
+  - required: the events with the zero value of the field are rejected
+  - redact: the non-zero values of the field are replaced by the default replacement
+
+For example:
+
+	type PromoCodeRedeemed struct {
+		Code   string `event:"code,required"`
+		UserID string `event:"user_id,redact"`
+		Amount int    `json:"amount"`
+		Note   string `event:"-"`
+	}
+*/
+type EventLog[T any] struct {
+	name    string
+	version int
+	fields  []eventField
+}
+
+/*
+NewEventLog declares an event log of the name & the schema version, the name is in snake case & is used in the default
+file name. A name can be declared again with the same type & version only, the schema version must be increased when the
+fields of T are changed.
+
+The event logs can be declared before or after Init.
+*/
+func NewEventLog[T any](name string, version int) (*EventLog[T], error) {
+	if !eventNamePattern.MatchString(name) {
+		return nil, errors.New(errors.BadRequest, fmt.Sprintf("invalid event log name %q", name))
+	}
+	if version < 1 {
+		return nil, errors.New(errors.BadRequest, fmt.Sprintf("invalid schema version %d of event log %s", version, name))
+	}
+
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, errors.New(errors.BadRequest, fmt.Sprintf("the events of %s are %s, not a struct", name, typ))
+	}
+	fields, err := eventFields(typ)
+	if err != nil {
+		return nil, errors.New(errors.BadRequest, "invalid schema of event log "+name, err)
+	}
+
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+	if schema, ok := eventSchemas[name]; ok && (schema.typ != typ || schema.version != version) {
+		return nil, errors.New(errors.Conflict,
+			fmt.Sprintf("event log %s is declared already with %s version %d", name, schema.typ, schema.version))
+	}
+	eventSchemas[name] = eventSchema{typ: typ, version: version}
+
+	return &EventLog[T]{name: name, version: version, fields: fields}, nil
+}
+
+// Name returns the name of the event log
+func (l *EventLog[T]) Name() string {
+	return l.name
+}
+
+// Version returns the schema version of the event log
+func (l *EventLog[T]) Version() int {
+	return l.version
+}
+
+// Log validates & logs an event, nothing is logged when Init is not called
+func (l *EventLog[T]) Log(event T) error {
+	v := reflect.ValueOf(event)
+
+	fields := make([]zap.Field, 0, len(l.fields)+2)
+	fields = append(fields, zap.String(eventKeyName, l.name), zap.Int(eventKeyVersion, l.version))
+	var missing []string
+	for _, f := range l.fields {
+		value, err := v.FieldByIndexErr(f.index)
+		if err != nil || value.IsZero() {
+			if f.required {
+				missing = append(missing, f.name)
+				continue
+			}
+			if err != nil {
+				// the field of a nil embedded struct pointer
+				continue
+			}
+		}
+
+		if f.redact && !value.IsZero() {
+			fields = append(fields, zap.String(f.name, defaultReplacement))
+		} else {
+			fields = append(fields, zap.Any(f.name, value.Interface()))
+		}
+	}
+	if len(missing) > 0 {
+		return errors.New(errors.BadRequest,
+			fmt.Sprintf("missing required fields of event %s: %s", l.name, strings.Join(missing, ", ")))
+	}
+
+	logger, err := eventLogger(l.name)
+	if err != nil {
+		return err
+	}
+	if logger != nil {
+		logger.Info("", fields...)
+	}
+	return nil
+}
+
+// eventFields returns the fields of an event struct, including the promoted fields of the embedded structs
+func eventFields(typ reflect.Type) ([]eventField, error) {
+	var fields []eventField
+	names := map[string]bool{eventKeyName: true, eventKeyVersion: true}
+	for _, sf := range reflect.VisibleFields(typ) {
+		if sf.Anonymous || !sf.IsExported() {
+			continue
+		}
+
+		f := eventField{index: sf.Index, name: sf.Name}
+		tag, ok := sf.Tag.Lookup(eventTag)
+		if !ok {
+			tag, _, _ = strings.Cut(sf.Tag.Get("json"), ",")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name != "" {
+			f.name = name
+		}
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "":
+			case "required":
+				f.required = ok
+			case "redact":
+				f.redact = ok
+			default:
+				if ok {
+					return nil, errors.New(fmt.Sprintf("unknown option %q of field %s", option, sf.Name))
+				}
+			}
+		}
+
+		if names[f.name] {
+			return nil, errors.New(fmt.Sprintf("duplicate field name %q", f.name))
+		}
+		names[f.name] = true
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// eventLogger returns the logger of an event log, or nil when Init is not called
+func eventLogger(name string) (*zap.Logger, error) {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+
+	if eventLoggers == nil {
+		return nil, nil
+	}
+	if logger, ok := eventLoggers[name]; ok {
+		return logger, nil
+	}
+
+	ws, err := eventSinks[name].writeSyncer(strings.Replace(eventsFileName, "{{name}}", name, 1))
+	if err != nil {
+		return nil, errors.New("cannot init "+name+" event logger", err)
+	}
+	logger := newLogger(ws)
+	eventLoggers[name] = logger
+	return logger, nil
+}
+
+// initEventLoggers sets the sinks of the event logs, the loggers of the previous Init are discarded
+func initEventLoggers(sinks map[string]Sink) {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+
+	eventSinks = sinks
+	eventLoggers = map[string]*zap.Logger{}
+}
+
+// dropEventLoggers drops the event logs until the next Init
+func dropEventLoggers() {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+
+	eventSinks = nil
+	eventLoggers = nil
+}
+
+func syncEventLoggers() {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+
+	for _, logger := range eventLoggers {
+		_ = logger.Sync()
+	}
+}
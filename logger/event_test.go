@@ -0,0 +1,185 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+type eventMeta struct {
+	Source string `event:"source"`
+}
+
+type promoCodeRedeemed struct {
+	eventMeta
+	Code    string  `event:"code,required"`
+	UserID  string  `event:"user_id,redact"`
+	Amount  float64 `json:"amount,omitempty"`
+	Note    string  `event:"-"`
+	Channel string
+	secret  string
+}
+
+func initEventLog(t *testing.T, name string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	err := logger.Init(logger.Config{
+		UltronEx:       stdout,
+		PartnerRequest: stdout,
+		Request:        stdout,
+		PromoCodeEvent: stdout,
+		Events:         map[string]logger.Sink{name: {WriteSyncer: zapcore.AddSync(&buf)}},
+	})
+	assert.NoError(t, err)
+	return &buf
+}
+
+func Test_EventLog_Log(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := logger.NewEventLog[promoCodeRedeemed]("promo_code_redeemed", 2)
+	assert.NoError(err)
+	assert.Equal("promo_code_redeemed", events.Name())
+	assert.Equal(2, events.Version())
+
+	buf := initEventLog(t, "promo_code_redeemed")
+	err = events.Log(promoCodeRedeemed{
+		eventMeta: eventMeta{Source: "checkout"},
+		Code:      "WEGO",
+		UserID:    "u-1",
+		Amount:    10.5,
+		Note:      "not logged",
+		Channel:   "app",
+		secret:    "not logged",
+	})
+	assert.NoError(err)
+	assert.NoError(events.Log(promoCodeRedeemed{Code: "WEGO2"}))
+	logger.Sync()
+
+	dec := json.NewDecoder(buf)
+	var first, second map[string]any
+	assert.NoError(dec.Decode(&first))
+	assert.NoError(dec.Decode(&second))
+	assert.Equal(map[string]any{
+		"event":          "promo_code_redeemed",
+		"schema_version": float64(2),
+		"source":         "checkout",
+		"code":           "WEGO",
+		"user_id":        "[Filtered by Wego]",
+		"amount":         10.5,
+		"Channel":        "app",
+	}, first)
+	assert.Equal(map[string]any{
+		"event":          "promo_code_redeemed",
+		"schema_version": float64(2),
+		"source":         "",
+		"code":           "WEGO2",
+		"user_id":        "",
+		"amount":         float64(0),
+		"Channel":        "",
+	}, second)
+}
+
+func Test_EventLog_Log_MissingRequiredFields(t *testing.T) {
+	assert := assert.New(t)
+
+	events, err := logger.NewEventLog[promoCodeRedeemed]("promo_code_redeemed", 2)
+	assert.NoError(err)
+
+	buf := initEventLog(t, "promo_code_redeemed")
+	err = events.Log(promoCodeRedeemed{UserID: "u-1"})
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "missing required fields of event promo_code_redeemed: code")
+	}
+	logger.Sync()
+	assert.Empty(buf.String())
+}
+
+func Test_EventLog_Log_DefaultFile(t *testing.T) {
+	assert := assert.New(t)
+
+	viper.Set("env", "test")
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	events, err := logger.NewEventLog[eventMeta]("booking_created", 1)
+	assert.NoError(err)
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	assert.NoError(logger.Init(logger.Config{
+		UltronEx:       stdout,
+		PartnerRequest: stdout,
+		Request:        stdout,
+		PromoCodeEvent: stdout,
+	}))
+	assert.NoError(events.Log(eventMeta{Source: "web"}))
+	logger.Sync()
+
+	content, err := os.ReadFile(filepath.Join(dir, "log", "booking_created_events.test.log"))
+	assert.NoError(err)
+	assert.JSONEq(`{"event":"booking_created","schema_version":1,"source":"web"}`, string(content))
+}
+
+func Test_NewEventLog_Invalid(t *testing.T) {
+	type unknownOption struct {
+		Code string `event:"code,mask"`
+	}
+	type duplicateName struct {
+		Code  string `event:"code"`
+		Other string `json:"code"`
+	}
+	type reservedName struct {
+		Version int `event:"schema_version"`
+	}
+
+	tests := map[string]func() error{
+		"invalid name": func() error {
+			_, err := logger.NewEventLog[eventMeta]("Promo-Code", 1)
+			return err
+		},
+		"invalid version": func() error {
+			_, err := logger.NewEventLog[eventMeta]("invalid_version", 0)
+			return err
+		},
+		"not a struct": func() error {
+			_, err := logger.NewEventLog[map[string]any]("not_a_struct", 1)
+			return err
+		},
+		"unknown option": func() error {
+			_, err := logger.NewEventLog[unknownOption]("unknown_option", 1)
+			return err
+		},
+		"duplicate name": func() error {
+			_, err := logger.NewEventLog[duplicateName]("duplicate_name", 1)
+			return err
+		},
+		"reserved name": func() error {
+			_, err := logger.NewEventLog[reservedName]("reserved_name", 1)
+			return err
+		},
+		"other type": func() error {
+			_, err := logger.NewEventLog[eventMeta]("promo_code_redeemed", 2)
+			return err
+		},
+		"other version": func() error {
+			_, err := logger.NewEventLog[promoCodeRedeemed]("promo_code_redeemed", 3)
+			return err
+		},
+	}
+
+	_, err := logger.NewEventLog[promoCodeRedeemed]("promo_code_redeemed", 2)
+	assert.NoError(t, err)
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, test())
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+
+	httpHeader "github.com/wego/pkg/http/header"
+)
+
+// HeaderStrategy is how the value of a sensitive header is masked
+type HeaderStrategy string
+
+// header strategies
+const (
+	// HeaderRedact replaces the whole value with the default replacement
+	HeaderRedact HeaderStrategy = "redact"
+	// HeaderMaskCredentials keeps the scheme, e.g. Bearer, & masks the credentials, showing their first 2 & last 3 chars
+	HeaderMaskCredentials HeaderStrategy = "mask_credentials"
+	// HeaderJWT keeps the scheme & the header of a JWT & redacts its claims & signature, the other credentials are
+	// masked like HeaderMaskCredentials
+	HeaderJWT HeaderStrategy = "jwt"
+	// HeaderCookie redacts the values of the sensitive cookies of a Cookie or Set-Cookie header
+	HeaderCookie HeaderStrategy = "cookie"
+)
+
+// credentialsMaskChar is the mask of the credentials of HeaderMaskCredentials
+const credentialsMaskChar = "***"
+
+// SensitiveHeader is how a sensitive header is masked
+type SensitiveHeader struct {
+	// Strategy is the strategy of the header, HeaderRedact is used when it's empty
+	Strategy HeaderStrategy
+	// PrefixesToSkip are the prefixes of the credentials kept before the shown chars, e.g. sk_test_, for
+	// HeaderMaskCredentials & HeaderJWT
+	PrefixesToSkip []string
+	// Cookies are the sensitive cookies of HeaderCookie, a cookie is sensitive when its name contains one of them,
+	// case-insensitive. The default sensitive cookies are used when it's empty.
+	Cookies []string
+}
+
+var (
+	// defaultSensitiveCookies are the parts of the names of the cookies holding sessions & credentials
+	defaultSensitiveCookies = []string{"session", "sid", "token", "auth", "jwt", "csrf", "xsrf", "secret", "key"}
+	// cookieAttributes are the attributes of a Set-Cookie header, which are not cookies
+	cookieAttributes = map[string]bool{
+		"expires": true, "max-age": true, "domain": true, "path": true, "secure": true, "httponly": true,
+		"samesite": true, "partitioned": true, "priority": true,
+	}
+)
+
+// sensitiveHeaders are the sensitive headers by lowercase name
+var sensitiveHeaders atomic.Pointer[map[string]SensitiveHeader]
+
+func init() {
+	SetSensitiveHeaders(DefaultSensitiveHeaders())
+}
+
+/*
+DefaultSensitiveHeaders returns the sensitive headers masked by default:
+  - Authorization & Wego-Authorization: HeaderJWT, skipping the pk_ & sk_ prefixes of the API keys
+  - Proxy-Authorization: HeaderMaskCredentials
+  - ApiKey, X-Api-Key & X-Auth-Token: HeaderRedact
+  - Cookie & Set-Cookie: HeaderCookie
+*/
+func DefaultSensitiveHeaders() map[string]SensitiveHeader {
+	apiKeyPrefixes := []string{"pk_test_", "sk_test_", "pk_", "sk_"}
+	return map[string]SensitiveHeader{
+		httpHeader.Authorization: {Strategy: HeaderJWT, PrefixesToSkip: apiKeyPrefixes},
+		httpHeader.WegoAuth:      {Strategy: HeaderJWT, PrefixesToSkip: apiKeyPrefixes},
+		"Proxy-Authorization":    {Strategy: HeaderMaskCredentials},
+		httpHeader.APIKey:        {Strategy: HeaderRedact},
+		"X-Api-Key":              {Strategy: HeaderRedact},
+		"X-Auth-Token":           {Strategy: HeaderRedact},
+		"Cookie":                 {Strategy: HeaderCookie},
+		"Set-Cookie":             {Strategy: HeaderCookie},
+	}
+}
+
+/*
+SetSensitiveHeaders replaces the sensitive headers masked in the logged requests, the names are case-insensitive. The
+default ones are extended with:
+
+	headers := logger.DefaultSensitiveHeaders()
+	headers["X-Partner-Key"] = logger.SensitiveHeader{Strategy: logger.HeaderRedact}
+	logger.SetSensitiveHeaders(headers)
+*/
+func SetSensitiveHeaders(headers map[string]SensitiveHeader) {
+	res := make(map[string]SensitiveHeader, len(headers))
+	for name, h := range headers {
+		res[strings.ToLower(name)] = h
+	}
+	sensitiveHeaders.Store(&res)
+}
+
+// MaskHeader returns the value of a header masked by its strategy, or the value itself when the header is not sensitive.
+// A masked value is not changed by masking it again.
+func MaskHeader(name, value string) string {
+	h, ok := (*sensitiveHeaders.Load())[strings.ToLower(name)]
+	if !ok {
+		return value
+	}
+
+	switch h.Strategy {
+	case HeaderMaskCredentials:
+		return maskCredentials(value, h.PrefixesToSkip)
+	case HeaderJWT:
+		return maskJWT(value, h.PrefixesToSkip)
+	case HeaderCookie:
+		return maskCookies(value, h.Cookies)
+	default:
+		return defaultReplacement
+	}
+}
+
+// maskCredentials masks the credentials after the scheme of the value
+func maskCredentials(value string, prefixesToSkip []string) string {
+	maskData := MaskData{
+		FirstCharsToShow: 2,
+		LastCharsToShow:  3,
+		KeepSameLength:   false,
+		prefixesToSkip:   prefixesToSkip,
+	}
+
+	if scheme, credentials, found := strings.Cut(value, " "); found {
+		return scheme + " " + getMaskedValue(credentialsMaskChar, credentials, maskData)
+	}
+
+	return getMaskedValue(credentialsMaskChar, value, maskData)
+}
+
+// maskJWT keeps the scheme & the header of a JWT, e.g. Bearer eyJhbGciOiJIUzI1NiJ9.[Filtered by Wego]
+func maskJWT(value string, prefixesToSkip []string) string {
+	scheme, token, found := strings.Cut(value, " ")
+	if !found {
+		scheme, token = "", value
+	}
+
+	jwtHeader, rest, found := strings.Cut(token, ".")
+	if found && rest == defaultReplacement && isJWTHeader(jwtHeader) {
+		// masked already
+		return value
+	}
+	if !found || strings.Count(token, ".") != 2 || !isJWTHeader(jwtHeader) {
+		return maskCredentials(value, prefixesToSkip)
+	}
+
+	res := jwtHeader + "." + defaultReplacement
+	if scheme != "" {
+		res = scheme + " " + res
+	}
+	return res
+}
+
+// isJWTHeader reports whether the segment is the base64url encoded JSON header of a JWT
+func isJWTHeader(segment string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return false
+	}
+
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	return json.Unmarshal(decoded, &h) == nil && h.Alg != ""
+}
+
+// maskCookies redacts the values of the sensitive cookies of a Cookie header, e.g. sid=1; theme=dark, or of a
+// Set-Cookie header, e.g. sid=1; Path=/; HttpOnly
+func maskCookies(value string, sensitiveCookies []string) string {
+	if len(sensitiveCookies) == 0 {
+		sensitiveCookies = defaultSensitiveCookies
+	}
+
+	pairs := strings.Split(value, ";")
+	for i, pair := range pairs {
+		name, _, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+
+		trimmed := strings.ToLower(strings.TrimSpace(name))
+		if cookieAttributes[trimmed] {
+			continue
+		}
+		for _, sensitive := range sensitiveCookies {
+			if strings.Contains(trimmed, strings.ToLower(sensitive)) {
+				pairs[i] = name + "=" + defaultReplacement
+				break
+			}
+		}
+	}
+	return strings.Join(pairs, ";")
+}
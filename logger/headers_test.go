@@ -0,0 +1,105 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+)
+
+const jwtHeader = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9"
+
+func Test_MaskHeader_Defaults(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		value    string
+		expected string
+	}{
+		"not sensitive": {
+			name:     "Content-Type",
+			value:    "application/json",
+			expected: "application/json",
+		},
+		"jwt": {
+			name:     "authorization",
+			value:    "Bearer " + jwtHeader + ".eyJzdWIiOiIxMjM0In0.c2lnbmF0dXJl",
+			expected: "Bearer " + jwtHeader + ".[Filtered by Wego]",
+		},
+		"masked jwt": {
+			name:     "Authorization",
+			value:    "Bearer " + jwtHeader + ".[Filtered by Wego]",
+			expected: "Bearer " + jwtHeader + ".[Filtered by Wego]",
+		},
+		"jwt without scheme": {
+			name:     "Wego-Authorization",
+			value:    jwtHeader + ".eyJzdWIiOiIxMjM0In0.c2lnbmF0dXJl",
+			expected: jwtHeader + ".[Filtered by Wego]",
+		},
+		"not a jwt": {
+			name:     "Authorization",
+			value:    "Bearer sk_test_1234567890",
+			expected: "Bearer sk_test_12***890",
+		},
+		"dots but not a jwt": {
+			name:     "AUTHORIZATION",
+			value:    "Basic abc.def.ghijkl",
+			expected: "Basic ab***jkl",
+		},
+		"proxy credentials": {
+			name:     "Proxy-Authorization",
+			value:    "Basic 123=4567890",
+			expected: "Basic 12***890",
+		},
+		"api key": {
+			name:     "apikey",
+			value:    "1234567890",
+			expected: "[Filtered by Wego]",
+		},
+		"x api key": {
+			name:     "X-API-KEY",
+			value:    "1234567890",
+			expected: "[Filtered by Wego]",
+		},
+		"cookie": {
+			name:     "Cookie",
+			value:    "theme=dark; SESSIONID=abc; _ga=GA1.2; csrf_token=xyz",
+			expected: "theme=dark; SESSIONID=[Filtered by Wego]; _ga=GA1.2; csrf_token=[Filtered by Wego]",
+		},
+		"set cookie": {
+			name:     "Set-Cookie",
+			value:    "auth=abc; Path=/; Max-Age=3600; HttpOnly; Secure",
+			expected: "auth=[Filtered by Wego]; Path=/; Max-Age=3600; HttpOnly; Secure",
+		},
+		"set cookie not sensitive": {
+			name:     "set-cookie",
+			value:    "theme=dark; Path=/",
+			expected: "theme=dark; Path=/",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(test.expected, logger.MaskHeader(test.name, test.value))
+		})
+	}
+}
+
+func Test_SetSensitiveHeaders(t *testing.T) {
+	assert := assert.New(t)
+
+	headers := logger.DefaultSensitiveHeaders()
+	headers["X-Partner-Key"] = logger.SensitiveHeader{}
+	headers["Cookie"] = logger.SensitiveHeader{Strategy: logger.HeaderCookie, Cookies: []string{"partner"}}
+	headers["Authorization"] = logger.SensitiveHeader{Strategy: logger.HeaderMaskCredentials}
+	logger.SetSensitiveHeaders(headers)
+	defer logger.SetSensitiveHeaders(logger.DefaultSensitiveHeaders())
+
+	assert.Equal("[Filtered by Wego]", logger.MaskHeader("x-partner-key", "secret"))
+	assert.Equal("sid=1; Partner_ID=[Filtered by Wego]", logger.MaskHeader("Cookie", "sid=1; Partner_ID=2"))
+	assert.Equal("Bearer sk***890", logger.MaskHeader("Authorization", "Bearer sk_test_1234567890"))
+	assert.Equal("[Filtered by Wego]", logger.MaskHeader("ApiKey", "secret"))
+
+	logger.SetSensitiveHeaders(nil)
+	assert.Equal("secret", logger.MaskHeader("ApiKey", "secret"))
+}
@@ -2,13 +2,10 @@ package logger
 
 import (
 	"context"
-	"os"
-	"path/filepath"
-	"strings"
 
-	"github.com/spf13/viper"
 	"github.com/wego/pkg/errors"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // ContextWithRequestType returns a new context from a parent context with request type added into it
@@ -49,33 +46,63 @@ func RequestFromContext(ctx context.Context) (req *Request) {
 	return
 }
 
-// LogUltronEx logs a msg to UltronEx local file
+// LogUltronEx logs a msg to UltronEx local file, without routing & throttling, see NewUltronEx
 func LogUltronEx(msg *UltronExMsg) {
-	logger := loggers[logTypeUltronex]
-	if logger != nil && msg != nil {
-		// UltronEx require the key as `msg`
-		logger.Info("", zap.Object("msg", msg))
+	if msg != nil {
+		_ = UltronExFileTransport{}.Send(context.Background(), detectUltronEx(msg))
 	}
 }
 
 // LogPartnerRequest logs a partner request to local file
 func LogPartnerRequest(log *Request) {
+	LogPartnerRequestContext(context.Background(), log)
+}
+
+// LogPartnerRequestContext logs a partner request to local file, with the trace & span IDs of the Datadog or
+// OpenTelemetry span of the context
+func LogPartnerRequestContext(ctx context.Context, log *Request) {
 	logger := loggers[logTypePartnerRequest]
 	if logger != nil && log != nil && len(log.Type) > 0 {
-		logger.Info("", log.fields()...)
+		log = withTrace(ctx, log)
+		if !enqueue(logTypePartnerRequest, log) {
+			logger.Info("", detectRequest(log, logTypePartnerRequest).fields()...)
+		}
 	}
 }
 
 // LogRequest logs a request to local file
 func LogRequest(log *Request) {
+	LogRequestContext(context.Background(), log)
+}
+
+// LogRequestContext logs a request to local file, with the trace & span IDs of the Datadog or OpenTelemetry span of
+// the context
+func LogRequestContext(ctx context.Context, log *Request) {
 	logger := loggers[logTypeRequest]
 	if logger != nil && log != nil && len(log.Type) > 0 {
-		logger.Info("", log.fields()...)
+		log = withTrace(ctx, log)
+		if !enqueue(logTypeRequest, log) {
+			logger.Info("", detectRequest(log, logTypeRequest).fields()...)
+		}
 	}
 }
 
+// withTrace returns a copy of the request with the span of the context, or the request itself when there is no span
+func withTrace(ctx context.Context, log *Request) *Request {
+	t := traceOf(ctx)
+	if t.isZero() {
+		return log
+	}
+
+	res := *log
+	res.trace = t
+	return &res
+}
+
 // LogPromoCodeEvent logs a promo-code event to a local file.
 // The event is logged as a flat JSON object (key/value pairs).
+//
+// Deprecated: declare the event with NewEventLog, so its fields are validated & versioned.
 func LogPromoCodeEvent(fields map[string]any) {
 	logger := loggers[logTypePromoCodeEvent]
 	if logger == nil || len(fields) == 0 {
@@ -89,64 +116,69 @@ func LogPromoCodeEvent(fields map[string]any) {
 	logger.Info("", zapFields...)
 }
 
-// Init initializes loggers
-func Init() error {
-	loggers = make(map[logType]*zap.Logger, 4)
-
-	uLog, err := initLogger(ultronExFileName)
-	if err != nil {
-		return errors.New("cannot init UltronEx logger", err)
-	}
-	loggers[logTypeUltronex] = uLog
+/*
+Init initializes the loggers with the sink of each log type, it disables the asynchronous mode & closes the sinks of
+the previous Init, the custom WriteSyncers are left open. When a sink cannot be built, the sinks already built are
+closed & the logs are dropped until the next Init, the application logs are written by the global zap logger.
+*/
+func Init(conf Config) (err error) {
+	DisableAsync()
+	closeSinks()
+	defer func() {
+		if err != nil {
+			closeSinks()
+			loggers = map[logType]*zap.Logger{}
+			writeSyncers = map[logType]zapcore.WriteSyncer{}
+			dropEventLoggers()
+			appLogger.Store(nil)
+		}
+	}()
 
-	prLog, err := initLogger(partnerRequestsFileName)
-	if err != nil {
-		return errors.New("cannot init partner request logger", err)
+	loggers = make(map[logType]*zap.Logger, 4)
+	writeSyncers = make(map[logType]zapcore.WriteSyncer, 4)
+
+	for _, s := range []struct {
+		logType  logType
+		sink     Sink
+		fileName string
+		name     string
+	}{
+		{logType: logTypeUltronex, sink: conf.UltronEx, fileName: ultronExFileName, name: "UltronEx"},
+		{logType: logTypePartnerRequest, sink: conf.PartnerRequest, fileName: partnerRequestsFileName, name: "partner request"},
+		{logType: logTypeRequest, sink: conf.Request, fileName: requestsFileName, name: "request"},
+		{logType: logTypePromoCodeEvent, sink: conf.PromoCodeEvent, fileName: promoCodeEventsFileName, name: "promo code event"},
+	} {
+		ws, err := s.sink.writeSyncer(s.fileName)
+		if err != nil {
+			return errors.New("cannot init "+s.name+" logger", err)
+		}
+		writeSyncers[s.logType] = ws
+		loggers[s.logType] = newLogger(ws)
 	}
-	loggers[logTypePartnerRequest] = prLog
 
-	rLog, err := initLogger(requestsFileName)
-	if err != nil {
-		return errors.New("cannot init request logger", err)
-	}
-	loggers[logTypeRequest] = rLog
+	initEventLoggers(conf.Events)
 
-	pcLog, err := initLogger(promoCodeEventsFileName)
+	appLog, err := newAppLogger(conf.App)
 	if err != nil {
-		return errors.New("cannot init promo code event logger", err)
+		return errors.New("cannot init app logger", err)
 	}
-	loggers[logTypePromoCodeEvent] = pcLog
+	appLevel.SetLevel(conf.AppLevel)
+	appLogger.Store(appLog)
 	return nil
 }
 
-// Sync syncs all loggers
+// Sync syncs all loggers, the queued logs of the asynchronous mode are written first
 func Sync() {
+	if a := asyncLog.Load(); a != nil {
+		a.flush()
+	}
 	for _, logger := range loggers {
 		if logger != nil {
 			logger.Sync()
 		}
 	}
-}
-
-func initLogger(fileName string) (logger *zap.Logger, err error) {
-	err = os.MkdirAll(logDir, os.ModePerm)
-	if err != nil {
-		return
+	syncEventLoggers()
+	if appLog := appLogger.Load(); appLog != nil {
+		_ = appLog.Sync()
 	}
-
-	logPath := filepath.Join(logDir, strings.Replace(fileName, "{{env}}", viper.GetString("env"), 1))
-	_, err = os.Create(logPath)
-	if err != nil {
-		return
-	}
-
-	logConfig := zap.NewProductionConfig()
-	// remove unwanted keys
-	logConfig.EncoderConfig.MessageKey = ""
-	logConfig.EncoderConfig.LevelKey = ""
-	logConfig.EncoderConfig.CallerKey = ""
-	logConfig.EncoderConfig.TimeKey = ""
-	// set output to file
-	logConfig.OutputPaths = []string{logPath}
-	return logConfig.Build()
 }
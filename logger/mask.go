@@ -31,7 +31,12 @@ type MaskData struct {
 	prefixesToSkip   []string
 }
 
-// MaskXML masks parts of the inner text of tags from the input XML with replacement
+/*
+MaskXML masks parts of the inner text of tags from the input XML with replacement, the masks are applied in order.
+
+The content of a matched tag is replaced by its masked inner text, including the tags of its child elements. Only the
+content of the matched tags is replaced, so the rest of the document is kept as is, see RedactXMLTargets.
+*/
 func MaskXML(xml, maskChar string, toMasks []MaskData) string {
 	maskChar = maskCharOrDefault(maskChar)
 
@@ -39,38 +44,46 @@ func MaskXML(xml, maskChar string, toMasks []MaskData) string {
 	if err != nil {
 		return errors.New("invalid XML input", err).Error()
 	}
-	out := findTagAndMaskMulti(doc, maskChar, toMasks)
-
-	return out
-}
+	for i, toMask := range toMasks {
+		// the document is parsed again after each mask, which may have replaced the elements found by the XPath
+		if i > 0 {
+			if doc, err = xmlquery.Parse(strings.NewReader(xml)); err != nil {
+				return errors.New("invalid XML input", err).Error()
+			}
+		}
 
-func findTagAndMaskMulti(doc *xmlquery.Node, maskChar string, toMasks []MaskData) string {
-	for _, toMask := range toMasks {
-		findTagAndMask(doc, maskChar, toMask)
+		matcher := &xmlMatcher{mask: func(text string) string { return getMaskedValue(maskChar, text, toMask) }}
+		if err = matcher.addXPath(doc, xpathOf(toMask.XMLTag)); err != nil {
+			return errors.New("invalid XPath", err).Error()
+		}
+		if len(matcher.texts) == 0 && len(matcher.attrs) == 0 {
+			continue
+		}
+		if xml, err = redactXMLBytes(xml, maskChar, matcher); err != nil {
+			return errors.New("invalid XML input", err).Error()
+		}
 	}
-	return doc.OutputXML(true)
+	return xml
 }
 
-func findTagAndMask(doc *xmlquery.Node, maskChar string, toMask MaskData) {
-	nodes := xmlquery.Find(doc, "//"+toMask.XMLTag)
-	for _, node := range nodes {
-		tagValue := node.InnerText()
-		if strings.TrimSpace(tagValue) != "" {
-			var nodeToUpdate *xmlquery.Node
-			// get specific node that contains the value to update
-			for child := node.FirstChild; child != nil; child = child.NextSibling {
-				nodeToUpdate = child
-			}
-			maskedVal := getMaskedValue(maskChar, tagValue, toMask)
-			nodeToUpdate.Data = maskedVal
-		}
+// xpathOf returns the XPath of a tag, which is either a full XPath, e.g. //Payment[@type='card']/Number, or a tag name
+// matched anywhere in the document
+func xpathOf(tag string) string {
+	if strings.HasPrefix(tag, "/") || strings.HasPrefix(tag, "(") {
+		return tag
 	}
+	return "//" + tag
 }
 
 /*
 MaskJSON mask parts of the json key paths value from the input json with replacement
 
 For nested arrays, use `[]` as the key.
+
+A single key starting with `$` is a JSONPath-style selector, see jsonSelector, e.g. `$..cardNumber` masks the
+cardNumber values at any depth & `$.passengers[*].documents[*].number` masks the numbers of all the documents.
+
+The values which are not masked are kept as they are, including their escapes.
 */
 func MaskJSON(json, maskChar string, toMasks []MaskData) string {
 	maskChar = maskCharOrDefault(maskChar)
@@ -82,6 +95,13 @@ func MaskJSON(json, maskChar string, toMasks []MaskData) string {
 	}
 
 	for _, toMask := range toMasks {
+		if isJSONSelector(toMask.JSONKeys) {
+			if err := maskSelector(root, toMask.JSONKeys[0], maskChar, toMask); err != nil {
+				return errors.New("invalid JSON selector", err).Error()
+			}
+			continue
+		}
+
 		l := len(toMask.JSONKeys)
 		switch {
 		case l == 1:
@@ -90,7 +110,7 @@ func MaskJSON(json, maskChar string, toMasks []MaskData) string {
 				value := getJSONValue(root.Get(toMask.JSONKeys[0]))
 				if value != "" {
 					maskedVal := getMaskedValue(maskChar, value, toMask)
-					replacement := fastjson.MustParse(`"` + maskedVal + `"`)
+					replacement := jsonString(maskedVal)
 					root.Set(toMask.JSONKeys[0], replacement)
 				}
 			}
@@ -116,7 +136,7 @@ func MaskJSON(json, maskChar string, toMasks []MaskData) string {
 					value := getJSONValue(v.Get(toMask.JSONKeys[l-1]))
 					if value != "" {
 						maskedVal := getMaskedValue(maskChar, value, toMask)
-						replacement := fastjson.MustParse(`"` + maskedVal + `"`)
+						replacement := jsonString(maskedVal)
 						v.Set(toMask.JSONKeys[l-1], replacement)
 					}
 				}
@@ -124,10 +144,27 @@ func MaskJSON(json, maskChar string, toMasks []MaskData) string {
 		}
 	}
 
+	// the keys are escaped the same way whether their objects are visited by the masks or not
+	unescapeKeys(root)
 	out := root.MarshalTo([]byte{})
 	return string(out)
 }
 
+// unescapeKeys unescapes the keys of the objects of the value, which fastjson only does for the objects being accessed,
+// not the string values, which are kept raw unless masked
+func unescapeKeys(v *fastjson.Value) {
+	// Type unescapes the raw strings, so check the kind of the value with GetObject & GetArray
+	if obj := v.GetObject(); obj != nil {
+		obj.Visit(func(_ []byte, child *fastjson.Value) {
+			unescapeKeys(child)
+		})
+		return
+	}
+	for _, child := range v.GetArray() {
+		unescapeKeys(child)
+	}
+}
+
 func maskArrayRecursive(obj *fastjson.Value, keys []string, maskChar string, toMask MaskData) {
 	if len(keys) == 0 || obj == nil {
 		return
@@ -170,7 +207,7 @@ func maskSingleProperty(obj *fastjson.Value, key string, maskChar string, toMask
 
 // setMaskedValue sets a masked string value on an object property.
 func setMaskedValue(obj *fastjson.Value, key string, maskedVal string) {
-	replacement := fastjson.MustParse(`"` + maskedVal + `"`)
+	replacement := jsonString(maskedVal)
 	obj.Set(key, replacement)
 }
 
@@ -217,10 +254,15 @@ func maskAllStringValues(obj *fastjson.Value, maskChar string, toMask MaskData)
 
 // setArrayItemMaskedValue sets a masked string value at a specific array index.
 func setArrayItemMaskedValue(arrayObj *fastjson.Value, index int, maskedVal string) {
-	replacement := fastjson.MustParse(`"` + maskedVal + `"`)
+	replacement := jsonString(maskedVal)
 	arrayObj.SetArrayItem(index, replacement)
 }
 
+// jsonString returns the JSON string value of s, which is escaped when it's marshalled
+func jsonString(s string) *fastjson.Value {
+	return (&fastjson.Arena{}).NewString(s)
+}
+
 func getJSONValue(jsonVal *fastjson.Value) string {
 	val := ""
 	if jsonVal != nil {
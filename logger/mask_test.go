@@ -126,6 +126,54 @@ func Test_MaskXML_Ok(t *testing.T) {
 	}
 }
 
+func Test_MaskXML_KeepsDocument(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		toMasks  []logger.MaskData
+		expected string
+	}{
+		"declaration, prefixes & whitespaces": {
+			input: `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <Card  type='visa'><Number>4111111111111111</Number><Holder/></Card>
+  </soap:Body>
+</soap:Envelope>`,
+			toMasks: []logger.MaskData{{XMLTag: "Number", LastCharsToShow: 4, KeepSameLength: true}},
+			expected: `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <Card  type='visa'><Number>************1111</Number><Holder/></Card>
+  </soap:Body>
+</soap:Envelope>`,
+		},
+		"tag with elements & masks in order": {
+			input: `<a><Card><Number>4111 1111</Number><!--c--><Expiry>12/30</Expiry></Card></a>`,
+			toMasks: []logger.MaskData{
+				{XMLTag: "Card", FirstCharsToShow: 2, LastCharsToShow: 2},
+				{XMLTag: "Number"},
+			},
+			expected: `<a><Card>41*30</Card></a>`,
+		},
+		"attributes": {
+			input:    `<a><Card Number="4111111111111111" Type="visa"/></a>`,
+			toMasks:  []logger.MaskData{{XMLTag: "//Card/@Number", LastCharsToShow: 4, KeepSameLength: true}},
+			expected: `<a><Card Number="************1111" Type="visa"/></a>`,
+		},
+		"escaped text": {
+			input:    `<a><Name>Jane &amp; Co</Name></a>`,
+			toMasks:  []logger.MaskData{{XMLTag: "Name", FirstCharsToShow: 6}},
+			expected: `<a><Name>Jane &amp;*</Name></a>`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, logger.MaskXML(test.input, "*", test.toMasks))
+		})
+	}
+}
+
 func parseXMLToString(xmlFileName string) (string, error) {
 	file, err := os.ReadFile(testFileDir + xmlFileName)
 	if err != nil {
@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	httpHeader "github.com/wego/pkg/http/header"
+)
+
+const defaultMaxBodySize = 64 << 10
+
+// MiddlewareConfig configures how the Middleware records inbound requests
+type MiddlewareConfig struct {
+	// Type is the type of the recorded requests
+	Type RequestType
+	// MaxBodySize is the number of bytes of the request & response bodies to record, 64KB is used when it's 0.
+	// The truncated bodies are recorded with a truncated marker, or as the marker only when they have rules to apply.
+	MaxBodySize int
+	// BodySampleRate is the rate, from 0 to 1, at which the bodies are recorded, they are always recorded when it's 0
+	BodySampleRate float64
+	// MaskChar is the char to mask the bodies with, the default mask char is used when it's empty
+	MaskChar string
+	// Replacement is the replacement of the redacted values, the default replacement is used when it's empty
+	Replacement string
+	// Routes are the rules of the routes, keyed by the method & the full path of the route, e.g. "POST /bookings/:id"
+	Routes map[string]RouteRule
+}
+
+// RouteRule is how the requests of a route are recorded
+type RouteRule struct {
+	// Skip opts the route out of the recording
+	Skip bool
+	// RequestMasks are the JSON key paths to mask in the request body.
+	// The masks & the redactions are applied on JSON bodies only, the bodies of other formats are recorded as a marker.
+	RequestMasks []MaskData
+	// ResponseMasks are the JSON key paths to mask in the response body
+	ResponseMasks []MaskData
+	// RequestRedacts are the JSON key paths to redact in the request body
+	RequestRedacts [][]string
+	// ResponseRedacts are the JSON key paths to redact in the response body
+	ResponseRedacts [][]string
+	// Policy is applied on the request & response bodies of the formats it supports, which are dispatched by their
+	// content types, as well as on the headers & the query of the URL. The bodies of other formats are recorded as a
+	// marker.
+	Policy *MaskingPolicy
+}
+
+func (r RouteRule) requestRules(maskChar, replacement string) bodyRules {
+	return bodyRules{
+		maskChar:    maskChar,
+		replacement: replacement,
+		masks:       r.RequestMasks,
+		redacts:     r.RequestRedacts,
+		policy:      r.Policy,
+	}
+}
+
+func (r RouteRule) responseRules(maskChar, replacement string) bodyRules {
+	return bodyRules{
+		maskChar:    maskChar,
+		replacement: replacement,
+		masks:       r.ResponseMasks,
+		redacts:     r.ResponseRedacts,
+		policy:      r.Policy,
+	}
+}
+
+// url returns the URL with the query masked by the policy
+func (r RouteRule) url(u *url.URL) string {
+	if r.Policy == nil || u.RawQuery == "" {
+		return u.String()
+	}
+
+	masked := *u
+	masked.RawQuery = r.Policy.ApplyQuery(u.RawQuery)
+	return masked.String()
+}
+
+/*
+Middleware returns a gin middleware recording each inbound request as a Request, which is logged by LogRequestContext
+with the span of the request context when the request completes. The Request is put into the context of the request
+by ContextWithRequest, so the handlers can add basics to it:
+
+	logger.RequestFromContext(c.Request.Context()).SetBasic("booking_id", id)
+*/
+func Middleware(conf MiddlewareConfig) gin.HandlerFunc {
+	if conf.MaxBodySize <= 0 {
+		conf.MaxBodySize = defaultMaxBodySize
+	}
+
+	return func(c *gin.Context) {
+		rule := conf.Routes[c.Request.Method+" "+c.FullPath()]
+		if rule.Skip {
+			c.Next()
+			return
+		}
+
+		req := &Request{
+			Type:           conf.Type,
+			Method:         c.Request.Method,
+			URL:            rule.url(c.Request.URL),
+			RequestHeaders: rule.Policy.ApplyHeaders(headersOf(c.Request.Header)),
+			IP:             httpHeader.ClientIP(c.Request),
+			RequestedAt:    time.Now(),
+		}
+		c.Request = c.Request.WithContext(ContextWithRequest(c.Request.Context(), req))
+
+		withBody := conf.BodySampleRate <= 0 || conf.BodySampleRate >= 1 || rand.Float64() < conf.BodySampleRate
+		var reqBody, resBody *cappedBuffer
+		if withBody {
+			reqBody = &cappedBuffer{max: conf.MaxBodySize}
+			resBody = &cappedBuffer{max: conf.MaxBodySize}
+			if c.Request.Body != nil && c.Request.Body != http.NoBody {
+				c.Request.Body = teeReadCloser{Reader: io.TeeReader(c.Request.Body, reqBody), Closer: c.Request.Body}
+			}
+			c.Writer = &bodyWriter{ResponseWriter: c.Writer, body: resBody}
+		}
+
+		c.Next()
+
+		req.StatusCode = int32(c.Writer.Status())
+		req.ResponseHeaders = rule.Policy.ApplyHeaders(headersOf(c.Writer.Header()))
+		req.Duration = time.Since(req.RequestedAt)
+		if len(c.Errors) > 0 {
+			req.Error = c.Errors.Last()
+		}
+		if withBody {
+			reqType, resType := c.Request.Header.Get(httpHeader.ContentType), c.Writer.Header().Get(httpHeader.ContentType)
+			req.RequestBody = reqBody.record(reqType, rule.requestRules(conf.MaskChar, conf.Replacement))
+			req.ResponseBody = resBody.record(resType, rule.responseRules(conf.MaskChar, conf.Replacement))
+		}
+
+		LogRequestContext(c.Request.Context(), req)
+	}
+}
+
+// headersOf joins the values of each header with ",", the values are masked one by one before, see MaskHeader, so e.g.
+// each Set-Cookie value is masked as a cookie
+func headersOf(h http.Header) Headers {
+	headers := make(Headers, len(h))
+	for name, values := range h {
+		masked := make([]string, len(values))
+		for i, value := range values {
+			masked[i] = MaskHeader(name, value)
+		}
+		headers[name] = strings.Join(masked, ",")
+	}
+	return headers
+}
+
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyWriter copies the response body into a buffer
+type bodyWriter struct {
+	gin.ResponseWriter
+	body *cappedBuffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	_, _ = w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	_, _ = w.body.Write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,197 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/http/header"
+	"github.com/wego/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+func initRequestLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	err := logger.Init(logger.Config{
+		UltronEx:       stdout,
+		PartnerRequest: stdout,
+		Request:        logger.Sink{WriteSyncer: zapcore.AddSync(&buf)},
+		PromoCodeEvent: stdout,
+	})
+	assert.NoError(t, err)
+	return &buf
+}
+
+func newMiddlewareRouter(conf logger.MiddlewareConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(logger.Middleware(conf))
+	r.POST("/bookings/:id", func(c *gin.Context) {
+		var body map[string]any
+		_ = c.ShouldBindJSON(&body)
+		logger.RequestFromContext(c.Request.Context()).SetBasic("booking_id", c.Param("id"))
+		c.Header("X-Booking", "1")
+		c.JSON(http.StatusCreated, gin.H{"email": "someone@wego.com", "token": "secret"})
+	})
+	r.POST("/payments", func(c *gin.Context) {
+		_ = c.PostForm("card_number")
+		c.Data(http.StatusOK, "application/xml", []byte(`<Payment><CardNumber>4111111111111111</CardNumber></Payment>`))
+	})
+	r.POST("/sessions", func(c *gin.Context) {
+		c.Writer.Header().Add("Set-Cookie", "sid=abc; Path=/")
+		c.Writer.Header().Add("Set-Cookie", "token=xyz; Expires=Wed, 21 Oct 2026 07:28:00 GMT; Path=/; HttpOnly")
+		c.Writer.Header().Add("Set-Cookie", "theme=dark; Path=/")
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/health", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+func Test_Middleware(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initRequestLog(t)
+	r := newMiddlewareRouter(logger.MiddlewareConfig{
+		Type: requestType,
+		Routes: map[string]logger.RouteRule{
+			"POST /bookings/:id": {
+				RequestRedacts:  [][]string{{"card_number"}},
+				ResponseMasks:   []logger.MaskData{{JSONKeys: []string{"email"}, FirstCharsToShow: 2, LastCharsToShow: 3}},
+				ResponseRedacts: [][]string{{"token"}},
+			},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/bookings/123?site=sg", strings.NewReader(`{"card_number":"4111111111111111"}`))
+	req.Header.Set(header.RealIP, "1.2.3.4")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(http.StatusCreated, w.Code)
+	assert.Equal(`{"email":"someone@wego.com","token":"secret"}`, w.Body.String())
+
+	logger.Sync()
+	var record map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(string(requestType), record["type"])
+	assert.Equal(http.MethodPost, record["method"])
+	assert.Equal("/bookings/123?site=sg", record["url"])
+	assert.Equal("1.2.3.4", record["ip"])
+	assert.Equal(`"123"`, record["booking_id"])
+	assert.Equal(float64(http.StatusCreated), record["status_code"])
+	assert.Equal(`{"card_number":"[Filtered by Wego]"}`, record["request_body"])
+	assert.Equal(`{"email":"so*com","token":"[Filtered by Wego]"}`, record["response_body"])
+	assert.Contains(record["response_headers"], map[string]any{"name": "X-Booking", "value": "1"})
+}
+
+func Test_Middleware_SetCookies(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initRequestLog(t)
+	r := newMiddlewareRouter(logger.MiddlewareConfig{Type: requestType})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/sessions", nil))
+	logger.Sync()
+
+	var record map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &record))
+	assert.Contains(record["response_headers"], map[string]any{
+		"name": "Set-Cookie",
+		"value": "sid=[Filtered by Wego]; Path=/," +
+			"token=[Filtered by Wego]; Expires=Wed, 21 Oct 2026 07:28:00 GMT; Path=/; HttpOnly," +
+			"theme=dark; Path=/",
+	})
+	assert.NotContains(buf.String(), "xyz")
+}
+
+func Test_Middleware_NonJSONBody(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initRequestLog(t)
+	r := newMiddlewareRouter(logger.MiddlewareConfig{
+		Type: requestType,
+		Routes: map[string]logger.RouteRule{
+			"POST /payments": {
+				RequestRedacts:  [][]string{{"card_number"}},
+				ResponseRedacts: [][]string{{"card_number"}},
+			},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader("card_number=4111111111111111"))
+	req.Header.Set(header.ContentType, header.ApplicationXFormURLEncoded)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	logger.Sync()
+
+	var record map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal("...[non-JSON body omitted]", record["request_body"])
+	assert.Equal("...[non-JSON body omitted]", record["response_body"])
+	assert.NotContains(buf.String(), "4111111111111111")
+}
+
+func Test_Middleware_Policy(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initRequestLog(t)
+	policy := &logger.MaskingPolicy{Rules: []logger.MaskingRule{{JSONPaths: []string{"$..token"}}}}
+	r := newMiddlewareRouter(logger.MiddlewareConfig{
+		Type: requestType,
+		Routes: map[string]logger.RouteRule{
+			"POST /bookings/:id": {Policy: policy},
+			"GET /health":        {Policy: policy},
+		},
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/bookings/123", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	logger.Sync()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(lines, 2) {
+		var record map[string]any
+		assert.NoError(json.Unmarshal([]byte(lines[0]), &record))
+		assert.Equal(`{"email":"someone@wego.com","token":"[Filtered by Wego]"}`, record["response_body"])
+		assert.NoError(json.Unmarshal([]byte(lines[1]), &record))
+		assert.Equal("...[unsupported body omitted]", record["response_body"])
+	}
+}
+
+func Test_Middleware_Skip(t *testing.T) {
+	buf := initRequestLog(t)
+	r := newMiddlewareRouter(logger.MiddlewareConfig{
+		Type:   requestType,
+		Routes: map[string]logger.RouteRule{"GET /health": {Skip: true}},
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	logger.Sync()
+
+	assert.Equal(t, "ok", w.Body.String())
+	assert.Empty(t, buf.String())
+}
+
+func Test_Middleware_MaxBodySize(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initRequestLog(t)
+	r := newMiddlewareRouter(logger.MiddlewareConfig{Type: requestType, MaxBodySize: 10})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/bookings/123", strings.NewReader(`{"name":"wego"}`)))
+	logger.Sync()
+
+	var record map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(`{"name":"w...[truncated]`, record["request_body"])
+	assert.Equal(`{"email":"...[truncated]`, record["response_body"])
+	assert.Equal(`{"email":"someone@wego.com","token":"secret"}`, w.Body.String())
+}
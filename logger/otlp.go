@@ -0,0 +1,362 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	goErrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wego/pkg/env"
+	"github.com/wego/pkg/errors"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	defaultOTLPBatchSize     = 100
+	defaultOTLPQueueSize     = 2048
+	defaultOTLPFlushInterval = 5 * time.Second
+	defaultOTLPTimeout       = 10 * time.Second
+	otlpScopeName            = "github.com/wego/pkg/logger"
+)
+
+// otlpSeverities are the severity numbers of the OpenTelemetry log data model by zap level, the records without level,
+// e.g. the request logs, are info
+var otlpSeverities = map[string]plog.SeverityNumber{
+	"debug":  plog.SeverityNumberDebug,
+	"info":   plog.SeverityNumberInfo,
+	"warn":   plog.SeverityNumberWarn,
+	"error":  plog.SeverityNumberError,
+	"dpanic": plog.SeverityNumberFatal,
+	"panic":  plog.SeverityNumberFatal,
+	"fatal":  plog.SeverityNumberFatal,
+}
+
+// OTLPConfig configures the export of the logs of a sink to an OpenTelemetry collector with OTLP/HTTP in JSON
+type OTLPConfig struct {
+	// Endpoint is the URL of the logs endpoint of the collector, e.g. http://localhost:4318/v1/logs
+	Endpoint string
+	// Headers are added to the export requests, e.g. an API key
+	Headers map[string]string
+	// BatchSize is the max number of records exported at once, 100 is used when it's 0
+	BatchSize int
+	// QueueSize is the number of records waiting to be exported, 2048 is used when it's 0. The records logged when the
+	// queue is full are dropped & reported by Sync.
+	QueueSize int
+	// FlushInterval is the interval to export the queued records at, 5s is used when it's 0
+	FlushInterval time.Duration
+	// Timeout is the timeout of an export request, 10s is used when it's 0
+	Timeout time.Duration
+	// Client makes the export requests, http.DefaultClient is used when it's nil
+	Client *http.Client
+}
+
+/*
+otlpWriter exports the JSON logs written to it as OTLP log records, each record has:
+  - the JSON log as its body
+  - the service, the version & the environment of env as the attributes of its resource
+  - the name of the log type as its log.name attribute
+  - the trace & span IDs of its trace correlation fields, see datadogIDs for the Datadog IDs
+
+The records are queued & exported in batches by a background goroutine, so the loggers never wait for the collector.
+The errors of the exports are returned by the next Sync. The requests are encoded by the OTLP JSON marshaler of the
+collector.
+*/
+type otlpWriter struct {
+	conf     OTLPConfig
+	name     string
+	resource pcommon.Resource
+
+	queue    chan otlpRecord
+	flushes  chan chan error
+	done     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+	dropped  atomic.Int64
+	// err is the error of the exports since the last Sync, it's only used by the goroutine
+	err error
+}
+
+// otlpWriters are the writers of the last Init, which are stopped by the next Init
+var (
+	otlpWritersMu sync.Mutex
+	otlpWriters   []*otlpWriter
+)
+
+func newOTLPWriter(conf *OTLPConfig, defaultFileName string) (*otlpWriter, error) {
+	if conf == nil || conf.Endpoint == "" {
+		return nil, errors.New(errors.BadRequest, "the OTLP sink has no endpoint")
+	}
+
+	w := &otlpWriter{
+		conf: *conf,
+		// e.g. requests.{{env}}.log -> requests
+		name:     strings.SplitN(defaultFileName, ".", 2)[0],
+		resource: pcommon.NewResource(),
+	}
+	w.resource.Attributes().PutStr("service.name", env.ServiceName())
+	w.resource.Attributes().PutStr("service.version", env.Version())
+	w.resource.Attributes().PutStr("deployment.environment", env.Env())
+	if w.conf.BatchSize <= 0 {
+		w.conf.BatchSize = defaultOTLPBatchSize
+	}
+	if w.conf.QueueSize <= 0 {
+		w.conf.QueueSize = defaultOTLPQueueSize
+	}
+	if w.conf.FlushInterval <= 0 {
+		w.conf.FlushInterval = defaultOTLPFlushInterval
+	}
+	if w.conf.Timeout <= 0 {
+		w.conf.Timeout = defaultOTLPTimeout
+	}
+	if w.conf.Client == nil {
+		w.conf.Client = http.DefaultClient
+	}
+	w.queue = make(chan otlpRecord, w.conf.QueueSize)
+	w.flushes = make(chan chan error)
+	w.done = make(chan struct{})
+	w.stopped = make(chan struct{})
+
+	otlpWritersMu.Lock()
+	otlpWriters = append(otlpWriters, w)
+	otlpWritersMu.Unlock()
+
+	go w.run()
+	return w, nil
+}
+
+// stopOTLPWriters exports the queued records of the writers of the previous Init & stops them
+func stopOTLPWriters() {
+	otlpWritersMu.Lock()
+	writers := otlpWriters
+	otlpWriters = nil
+	otlpWritersMu.Unlock()
+
+	for _, w := range writers {
+		w.stop()
+	}
+}
+
+// Write queues a record by JSON log of p, the records are dropped when the queue is full
+func (w *otlpWriter) Write(p []byte) (int, error) {
+	now := pcommon.NewTimestampFromTime(time.Now())
+
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		select {
+		case w.queue <- w.record(line, now):
+		default:
+			w.dropped.Add(1)
+		}
+	}
+	return len(p), nil
+}
+
+// Sync waits until the queued records are exported, it returns the errors of the exports since the last Sync
+func (w *otlpWriter) Sync() error {
+	flushed := make(chan error, 1)
+	select {
+	case w.flushes <- flushed:
+		return <-flushed
+	case <-w.stopped:
+		return nil
+	}
+}
+
+// stop exports the queued records & stops the goroutine
+func (w *otlpWriter) stop() {
+	w.stopOnce.Do(func() { close(w.done) })
+	<-w.stopped
+}
+
+func (w *otlpWriter) run() {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(w.conf.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]otlpRecord, 0, w.conf.BatchSize)
+	for {
+		select {
+		case r := <-w.queue:
+			if batch = append(batch, r); len(batch) >= w.conf.BatchSize {
+				batch = w.export(batch)
+			}
+		case <-ticker.C:
+			batch = w.export(batch)
+		case flushed := <-w.flushes:
+			batch = w.exportQueued(batch)
+			err := w.err
+			if dropped := w.dropped.Swap(0); dropped > 0 {
+				err = goErrors.Join(err, errors.New(fmt.Sprintf("%d OTLP log records dropped, the queue is full", dropped)))
+			}
+			w.err = nil
+			flushed <- err
+		case <-w.done:
+			w.exportQueued(batch)
+			return
+		}
+	}
+}
+
+// exportQueued exports the batch & the queued records in batches, it returns the emptied batch
+func (w *otlpWriter) exportQueued(batch []otlpRecord) []otlpRecord {
+	for {
+		select {
+		case r := <-w.queue:
+			if batch = append(batch, r); len(batch) >= w.conf.BatchSize {
+				batch = w.export(batch)
+			}
+		default:
+			return w.export(batch)
+		}
+	}
+}
+
+// export sends the records to the collector & returns the emptied batch, the records are dropped when the export
+// fails, so a collector down does not pile them up
+func (w *otlpWriter) export(records []otlpRecord) []otlpRecord {
+	if len(records) == 0 {
+		return records
+	}
+	if err := w.send(records); err != nil {
+		w.err = goErrors.Join(w.err, err)
+	}
+	return records[:0]
+}
+
+// otlpRecord is a log record waiting to be exported
+type otlpRecord struct {
+	time         pcommon.Timestamp
+	severity     plog.SeverityNumber
+	severityText string
+	body         string
+	traceID      pcommon.TraceID
+	spanID       pcommon.SpanID
+}
+
+func (w *otlpWriter) record(line []byte, now pcommon.Timestamp) otlpRecord {
+	r := otlpRecord{
+		time:         now,
+		severity:     plog.SeverityNumberInfo,
+		severityText: "INFO",
+		body:         string(line),
+	}
+
+	var fields struct {
+		Level          string `json:"level"`
+		TraceID        string `json:"trace_id"`
+		SpanID         string `json:"span_id"`
+		DDTraceID      string `json:"dd.trace_id"`
+		DDTraceIDUpper string `json:"_dd.p.tid"`
+		DDSpanID       string `json:"dd.span_id"`
+	}
+	if json.Unmarshal(line, &fields) != nil {
+		return r
+	}
+
+	if severity, ok := otlpSeverities[fields.Level]; ok {
+		r.severity = severity
+		r.severityText = strings.ToUpper(fields.Level)
+	}
+	switch {
+	case fields.TraceID != "":
+		decodeHexID(r.traceID[:], fields.TraceID)
+		decodeHexID(r.spanID[:], fields.SpanID)
+	case fields.DDTraceID != "":
+		r.traceID, r.spanID = datadogIDs(fields.DDTraceIDUpper, fields.DDTraceID, fields.DDSpanID)
+	}
+	return r
+}
+
+/*
+datadogIDs converts the Datadog decimal IDs to OpenTelemetry IDs. The Datadog trace ID is the lower 64 bits of the
+128 bits trace ID, whose upper 64 bits are the hex of the _dd.p.tid tag, so the trace ID matches the one of the
+OpenTelemetry bridge of Datadog. The upper 64 bits are zeros when the tag is missing, e.g. for the 64 bits trace IDs.
+The IDs are empty when they're invalid.
+*/
+func datadogIDs(upper, lower, span string) (traceID pcommon.TraceID, spanID pcommon.SpanID) {
+	lowerID, lowerErr := strconv.ParseUint(lower, 10, 64)
+	spanIDValue, spanErr := strconv.ParseUint(span, 10, 64)
+	if lowerErr != nil || spanErr != nil {
+		return
+	}
+	if upperID, err := strconv.ParseUint(upper, 16, 64); err == nil && len(upper) == 16 {
+		binary.BigEndian.PutUint64(traceID[:8], upperID)
+	}
+	binary.BigEndian.PutUint64(traceID[8:], lowerID)
+	binary.BigEndian.PutUint64(spanID[:], spanIDValue)
+	return
+}
+
+// decodeHexID decodes the hex ID into id, which is left empty when the ID is invalid
+func decodeHexID(id []byte, s string) {
+	if len(s) != hex.EncodedLen(len(id)) {
+		return
+	}
+	if _, err := hex.Decode(id, []byte(s)); err != nil {
+		clear(id)
+	}
+}
+
+// send posts the records to the collector
+func (w *otlpWriter) send(records []otlpRecord) error {
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	w.resource.CopyTo(resourceLogs.Resource())
+	scopeLogs := resourceLogs.ScopeLogs().AppendEmpty()
+	scopeLogs.Scope().SetName(otlpScopeName)
+	logRecords := scopeLogs.LogRecords()
+	logRecords.EnsureCapacity(len(records))
+	for _, r := range records {
+		record := logRecords.AppendEmpty()
+		record.SetTimestamp(r.time)
+		record.SetObservedTimestamp(r.time)
+		record.SetSeverityNumber(r.severity)
+		record.SetSeverityText(r.severityText)
+		record.Body().SetStr(r.body)
+		record.Attributes().PutStr("log.name", w.name)
+		record.SetTraceID(r.traceID)
+		record.SetSpanID(r.spanID)
+	}
+
+	body, err := (&plog.JSONMarshaler{}).MarshalLogs(logs)
+	if err != nil {
+		return errors.New("cannot marshal OTLP logs", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.conf.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.conf.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("cannot create OTLP request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.conf.Headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := w.conf.Client.Do(req)
+	if err != nil {
+		return errors.New("cannot export OTLP logs", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return errors.New(fmt.Sprintf("OTLP collector responded with status %d", res.StatusCode))
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/mocktracer"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpRecord struct {
+	SeverityNumber int             `json:"severityNumber"`
+	SeverityText   string          `json:"severityText"`
+	Body           otlpValue       `json:"body"`
+	Attributes     []otlpAttribute `json:"attributes"`
+	TraceID        string          `json:"traceId"`
+	SpanID         string          `json:"spanId"`
+}
+
+type otlpExport struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []otlpAttribute `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []struct {
+			Scope struct {
+				Name string `json:"name"`
+			} `json:"scope"`
+			LogRecords []otlpRecord `json:"logRecords"`
+		} `json:"scopeLogs"`
+	} `json:"resourceLogs"`
+}
+
+// otlpCollector is a local stand-in of an OpenTelemetry collector, which records the exports,
+// an export is rejected unless the collector's own OTLP JSON decoder accepts it
+type otlpCollector struct {
+	*httptest.Server
+	mu      sync.Mutex
+	exports []otlpExport
+	headers []http.Header
+}
+
+func newOTLPCollector(t *testing.T) *otlpCollector {
+	c := &otlpCollector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil || r.URL.Path != "/v1/logs" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var export otlpExport
+		if plogotlp.NewExportRequest().UnmarshalJSON(body) != nil || json.Unmarshal(body, &export) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.exports = append(c.exports, export)
+		c.headers = append(c.headers, r.Header.Clone())
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func (c *otlpCollector) records() []otlpRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var records []otlpRecord
+	for _, export := range c.exports {
+		for _, resourceLogs := range export.ResourceLogs {
+			for _, scopeLogs := range resourceLogs.ScopeLogs {
+				records = append(records, scopeLogs.LogRecords...)
+			}
+		}
+	}
+	return records
+}
+
+func hexUint(t *testing.T, s string) uint64 {
+	t.Helper()
+
+	v, err := strconv.ParseUint(s, 16, 64)
+	assert.NoError(t, err)
+	return v
+}
+
+func Test_SinkOTLP(t *testing.T) {
+	assert := assert.New(t)
+
+	viper.Set("service_name", "bookings")
+	collector := newOTLPCollector(t)
+	otlp := logger.Sink{Type: logger.SinkOTLP, OTLP: &logger.OTLPConfig{
+		Endpoint:  collector.URL + "/v1/logs",
+		Headers:   map[string]string{"Api-Key": "key"},
+		BatchSize: 2,
+	}}
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	assert.NoError(logger.Init(logger.Config{
+		UltronEx:       stdout,
+		PartnerRequest: stdout,
+		Request:        otlp,
+		PromoCodeEvent: stdout,
+		App:            otlp,
+	}))
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "partner.search")
+	defer span.Finish()
+
+	logger.LogRequestContext(ctx, &logger.Request{Type: requestType, URL: "/bookings"})
+	logger.LogRequest(&logger.Request{Type: requestType, URL: "/health"})
+	assert.Eventually(func() bool { return len(collector.records()) == 2 }, time.Second, 10*time.Millisecond,
+		"a batch is exported once it's complete")
+
+	logger.FromContext(context.Background()).Warn("slow partner", zap.Int("duration_in_ms", 3000))
+	logger.Sync()
+
+	records := collector.records()
+	if assert.Len(records, 3) {
+		assert.Equal(9, records[0].SeverityNumber)
+		assert.Equal("INFO", records[0].SeverityText)
+		assert.Contains(records[0].Body.StringValue, `"url":"/bookings"`)
+		ddTraceID := strconv.FormatUint(span.Context().TraceID(), 10)
+		assert.Contains(records[0].Body.StringValue, `"dd.trace_id":"`+ddTraceID+`"`)
+		assert.Equal([]otlpAttribute{{Key: "log.name", Value: otlpValue{StringValue: "requests"}}}, records[0].Attributes)
+		assert.Len(records[0].TraceID, 32)
+		assert.Len(records[0].SpanID, 16)
+		assert.Equal(span.Context().TraceID(), hexUint(t, records[0].TraceID[16:]))
+		assert.Equal(span.Context().SpanID(), hexUint(t, records[0].SpanID))
+
+		assert.Contains(records[1].Body.StringValue, `"url":"/health"`)
+		assert.Empty(records[1].TraceID)
+
+		assert.Equal(13, records[2].SeverityNumber)
+		assert.Equal("WARN", records[2].SeverityText)
+		assert.Contains(records[2].Body.StringValue, `"msg":"slow partner"`)
+		assert.Equal("app", records[2].Attributes[0].Value.StringValue)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	assert.Equal("key", collector.headers[0].Get("Api-Key"))
+	assert.Equal("application/json", collector.headers[0].Get("Content-Type"))
+	resource := collector.exports[0].ResourceLogs[0].Resource.Attributes
+	assert.Contains(resource, otlpAttribute{Key: "service.name", Value: otlpValue{StringValue: "bookings"}})
+	assert.Equal("github.com/wego/pkg/logger", collector.exports[0].ResourceLogs[0].ScopeLogs[0].Scope.Name)
+}
+
+func Test_SinkOTLP_OpenTelemetrySpan(t *testing.T) {
+	assert := assert.New(t)
+
+	collector := newOTLPCollector(t)
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	assert.NoError(logger.Init(logger.Config{
+		UltronEx: stdout,
+		PartnerRequest: logger.Sink{Type: logger.SinkOTLP, OTLP: &logger.OTLPConfig{
+			Endpoint: collector.URL + "/v1/logs",
+		}},
+		Request:        stdout,
+		PromoCodeEvent: stdout,
+	}))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x0a, 0x0b, 15: 0x01},
+		SpanID:     trace.SpanID{0x0c, 7: 0x02},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	logger.LogPartnerRequestContext(ctx, &logger.Request{Type: requestType})
+	logger.Sync()
+
+	records := collector.records()
+	if assert.Len(records, 1) {
+		assert.Equal(sc.TraceID().String(), records[0].TraceID)
+		assert.Equal(sc.SpanID().String(), records[0].SpanID)
+		assert.Contains(records[0].Body.StringValue, `"trace_id":"`+sc.TraceID().String()+`"`)
+		assert.Equal("partner_requests", records[0].Attributes[0].Value.StringValue)
+	}
+}
+
+// ddSpanContext128 is the context of a Datadog span with a 128 bits trace ID, which the mock tracer doesn't generate
+type ddSpanContext128 struct {
+	traceID [16]byte
+	spanID  uint64
+}
+
+func (c ddSpanContext128) SpanID() uint64                            { return c.spanID }
+func (c ddSpanContext128) TraceID() uint64                           { return binary.BigEndian.Uint64(c.traceID[8:]) }
+func (c ddSpanContext128) ForeachBaggageItem(func(k, v string) bool) {}
+func (c ddSpanContext128) TraceID128() string                        { return hex.EncodeToString(c.traceID[:]) }
+func (c ddSpanContext128) TraceID128Bytes() [16]byte                 { return c.traceID }
+
+type ddSpan128 struct {
+	ddtrace.Span
+	ctx ddSpanContext128
+}
+
+func (s ddSpan128) Context() ddtrace.SpanContext { return s.ctx }
+
+func Test_SinkOTLP_DatadogTraceID128(t *testing.T) {
+	assert := assert.New(t)
+
+	collector := newOTLPCollector(t)
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	assert.NoError(logger.Init(logger.Config{
+		UltronEx: stdout,
+		PartnerRequest: logger.Sink{Type: logger.SinkOTLP, OTLP: &logger.OTLPConfig{
+			Endpoint: collector.URL + "/v1/logs",
+		}},
+		Request:        stdout,
+		PromoCodeEvent: stdout,
+	}))
+
+	sc := ddSpanContext128{
+		traceID: [16]byte{0x67, 0x0f, 0x1a, 0x2b, 7: 0x01, 8: 0x0a, 15: 0x02},
+		spanID:  3,
+	}
+	ctx := tracer.ContextWithSpan(context.Background(), ddSpan128{ctx: sc})
+	logger.LogPartnerRequestContext(ctx, &logger.Request{Type: requestType})
+	logger.Sync()
+
+	records := collector.records()
+	if assert.Len(records, 1) {
+		assert.Equal(sc.TraceID128(), records[0].TraceID, "the upper 64 bits come from the _dd.p.tid tag")
+		assert.Equal("0000000000000003", records[0].SpanID)
+		assert.Contains(records[0].Body.StringValue, `"_dd.p.tid":"`+sc.TraceID128()[:16]+`"`)
+	}
+}
+
+func Test_SinkOTLP_WithoutEndpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	err := logger.Init(logger.Config{
+		UltronEx:       stdout,
+		PartnerRequest: stdout,
+		Request:        logger.Sink{Type: logger.SinkOTLP},
+		PromoCodeEvent: stdout,
+	})
+	assert.Error(err)
+}
+
+func Test_SinkOTLP_FlushInterval(t *testing.T) {
+	assert := assert.New(t)
+
+	collector := newOTLPCollector(t)
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	assert.NoError(logger.Init(logger.Config{
+		UltronEx:       stdout,
+		PartnerRequest: stdout,
+		Request: logger.Sink{Type: logger.SinkOTLP, OTLP: &logger.OTLPConfig{
+			Endpoint:      collector.URL + "/v1/logs",
+			FlushInterval: 20 * time.Millisecond,
+		}},
+		PromoCodeEvent: stdout,
+	}))
+
+	logger.LogRequest(&logger.Request{Type: requestType, URL: "/bookings"})
+	assert.Eventually(func() bool { return len(collector.records()) == 1 }, time.Second, 10*time.Millisecond,
+		"the queued records are exported at the flush interval")
+}
+
+func Test_SinkOTLP_SlowCollector(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	assert.NoError(logger.Init(logger.Config{
+		UltronEx:       stdout,
+		PartnerRequest: stdout,
+		Request: logger.Sink{Type: logger.SinkOTLP, OTLP: &logger.OTLPConfig{
+			Endpoint:  collector.URL + "/v1/logs",
+			BatchSize: 1,
+			QueueSize: 2,
+		}},
+		PromoCodeEvent: stdout,
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			logger.LogRequest(&logger.Request{Type: requestType, URL: "/bookings"})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail("the logs wait for the collector")
+	}
+
+	close(release)
+	// Init exports the queued records of the previous sinks
+	assert.NoError(logger.Init(logger.Config{
+		UltronEx:       stdout,
+		PartnerRequest: stdout,
+		Request:        stdout,
+		PromoCodeEvent: stdout,
+	}))
+}
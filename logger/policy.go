@@ -0,0 +1,272 @@
+package logger
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/wego/pkg/errors"
+	httpHeader "github.com/wego/pkg/http/header"
+)
+
+// MaskingAction is how the values matched by a MaskingRule are hidden
+type MaskingAction string
+
+// masking actions
+const (
+	// MaskingActionMask masks parts of the values, see MaskData
+	MaskingActionMask MaskingAction = "mask"
+	// MaskingActionRedact replaces the whole values with the replacement
+	MaskingActionRedact MaskingAction = "redact"
+)
+
+const jsonPathSeparator = "."
+
+// MaskingRule lists the values to hide in each format & how to hide them
+type MaskingRule struct {
+	// Action is how the values are hidden, the values are redacted when it's empty
+	Action MaskingAction `mapstructure:"action"`
+	// JSONPaths are the key paths in JSON bodies separated by ".", use `[]` for arrays, e.g. passengers.[].email,
+	// or JSONPath-style selectors starting with `$`, e.g. $..card_number
+	JSONPaths []string `mapstructure:"json_paths"`
+	// XMLTags are the tags in XML bodies, or XPaths starting with `/`, e.g. //Payment[@type='card']/Number
+	XMLTags []string `mapstructure:"xml_tags"`
+	// FormKeys are the keys in form URL encoded bodies
+	FormKeys []string `mapstructure:"form_keys"`
+	// QueryParams are the query parameters of URLs
+	QueryParams []string `mapstructure:"query_params"`
+	// Headers are the names of the headers, case-insensitive
+	Headers []string `mapstructure:"headers"`
+
+	// the options of MaskingActionMask, see MaskData
+	FirstCharsToShow int                 `mapstructure:"first_chars_to_show"`
+	LastCharsToShow  int                 `mapstructure:"last_chars_to_show"`
+	KeepSameLength   bool                `mapstructure:"keep_same_length"`
+	RestrictionType  MaskRestrictionType `mapstructure:"restriction_type"`
+	CharsToIgnore    string              `mapstructure:"chars_to_ignore"`
+}
+
+/*
+MaskingPolicy is the masking rules of the logs of a partner or a service, which is usually loaded from a config file
+by LoadMaskingPolicy, e.g. in YAML:
+
+	masking:
+	  mask_char: "*"
+	  rules:
+	    - action: mask
+	      json_paths: ["passengers.[].email"]
+	      xml_tags: [Email]
+	      first_chars_to_show: 2
+	      last_chars_to_show: 4
+	      chars_to_ignore: "@"
+	    - action: redact
+	      json_paths: [payment.card_number]
+	      form_keys: [card_number]
+	      query_params: [api_key]
+	      headers: [X-Api-Key]
+*/
+type MaskingPolicy struct {
+	// MaskChar is the char to mask the values with, the default mask char is used when it's empty
+	MaskChar string `mapstructure:"mask_char"`
+	// Replacement is the replacement of the redacted values, the default replacement is used when it's empty
+	Replacement string `mapstructure:"replacement"`
+	// Rules are applied in order
+	Rules []MaskingRule `mapstructure:"rules"`
+}
+
+// LoadMaskingPolicy loads the masking policy under the key of the viper config, the global viper is used when v is nil
+func LoadMaskingPolicy(v *viper.Viper, key string) (*MaskingPolicy, error) {
+	if v == nil {
+		v = viper.GetViper()
+	}
+	if !v.IsSet(key) {
+		return nil, errors.New(errors.NotFound, "masking policy not found: "+key)
+	}
+
+	policy := &MaskingPolicy{}
+	if err := v.UnmarshalKey(key, policy); err != nil {
+		return nil, errors.New("invalid masking policy: "+key, err)
+	}
+
+	for _, rule := range policy.Rules {
+		switch rule.Action {
+		case "", MaskingActionMask, MaskingActionRedact:
+		default:
+			return nil, errors.New(errors.BadRequest, "invalid masking action: "+string(rule.Action))
+		}
+	}
+	return policy, nil
+}
+
+/*
+Apply applies the rules on a body of the content type, which is dispatched as below:
+  - application/json & +json types -> JSONPaths
+  - application/xml, text/xml & +xml types -> XMLTags
+  - application/x-www-form-urlencoded -> FormKeys
+
+The bodies of other content types are returned as is.
+*/
+func (p *MaskingPolicy) Apply(contentType, body string) string {
+	if p == nil || body == "" {
+		return body
+	}
+
+	switch formatOf(contentType) {
+	case formatJSON:
+		for _, rule := range p.Rules {
+			body = p.applyJSON(rule, body)
+		}
+	case formatXML:
+		for _, rule := range p.Rules {
+			body = p.applyXML(rule, body)
+		}
+	case formatForm:
+		for _, rule := range p.Rules {
+			body = p.applyForm(rule, body)
+		}
+	}
+	return body
+}
+
+// bodyFormat is the format of a body, which decides the rules applied on it
+type bodyFormat int
+
+const (
+	formatOther bodyFormat = iota
+	formatJSON
+	formatXML
+	formatForm
+)
+
+// formatOf returns the format of the bodies of a content type
+func formatOf(contentType string) bodyFormat {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	switch {
+	case err != nil:
+		return formatOther
+	case mediaType == httpHeader.ApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		return formatJSON
+	case mediaType == httpHeader.ApplicationXML || mediaType == httpHeader.TextXML || strings.HasSuffix(mediaType, "+xml"):
+		return formatXML
+	case mediaType == httpHeader.ApplicationXFormURLEncoded:
+		return formatForm
+	}
+	return formatOther
+}
+
+// ApplyQuery applies the rules on the raw query of a URL
+func (p *MaskingPolicy) ApplyQuery(rawQuery string) string {
+	if p == nil {
+		return rawQuery
+	}
+
+	for _, rule := range p.Rules {
+		if len(rule.QueryParams) == 0 {
+			continue
+		}
+		if rule.Action == MaskingActionMask {
+			rawQuery = MaskQueryParams(rawQuery, p.MaskChar, []MaskData{rule.maskData(rule.QueryParams)})
+		} else {
+			rawQuery = RedactQueryParams(rawQuery, p.Replacement, rule.QueryParams)
+		}
+	}
+	return rawQuery
+}
+
+// ApplyHeaders returns a copy of the headers with the rules applied
+func (p *MaskingPolicy) ApplyHeaders(headers Headers) Headers {
+	if p == nil || headers == nil {
+		return headers
+	}
+
+	res := make(Headers, len(headers))
+	for name, value := range headers {
+		for _, rule := range p.Rules {
+			if !containsFold(rule.Headers, name) {
+				continue
+			}
+			if rule.Action == MaskingActionMask {
+				value = getMaskedValue(maskCharOrDefault(p.MaskChar), value, rule.maskData(nil))
+			} else {
+				value = replacementCharOrDefault(p.Replacement)
+			}
+		}
+		res[name] = value
+	}
+	return res
+}
+
+func (p *MaskingPolicy) applyJSON(rule MaskingRule, body string) string {
+	if len(rule.JSONPaths) == 0 {
+		return body
+	}
+
+	paths := make([][]string, 0, len(rule.JSONPaths))
+	for _, path := range rule.JSONPaths {
+		if strings.HasPrefix(path, selectorRoot) {
+			paths = append(paths, []string{path})
+			continue
+		}
+		paths = append(paths, strings.Split(path, jsonPathSeparator))
+	}
+
+	if rule.Action != MaskingActionMask {
+		return RedactJSON(body, p.Replacement, paths)
+	}
+
+	toMasks := make([]MaskData, 0, len(paths))
+	for _, path := range paths {
+		toMasks = append(toMasks, rule.maskData(path))
+	}
+	return MaskJSON(body, p.MaskChar, toMasks)
+}
+
+func (p *MaskingPolicy) applyXML(rule MaskingRule, body string) string {
+	if len(rule.XMLTags) == 0 {
+		return body
+	}
+
+	if rule.Action != MaskingActionMask {
+		return RedactXML(body, p.Replacement, rule.XMLTags)
+	}
+
+	toMasks := make([]MaskData, 0, len(rule.XMLTags))
+	for _, tag := range rule.XMLTags {
+		toMask := rule.maskData(nil)
+		toMask.XMLTag = tag
+		toMasks = append(toMasks, toMask)
+	}
+	return MaskXML(body, p.MaskChar, toMasks)
+}
+
+func (p *MaskingPolicy) applyForm(rule MaskingRule, body string) string {
+	if len(rule.FormKeys) == 0 {
+		return body
+	}
+
+	if rule.Action != MaskingActionMask {
+		return RedactFormURLEncoded(body, p.Replacement, rule.FormKeys)
+	}
+	return MaskFormURLEncoded(body, p.MaskChar, []MaskData{rule.maskData(rule.FormKeys)})
+}
+
+// maskData returns the mask options of the rule for the keys
+func (r MaskingRule) maskData(keys []string) MaskData {
+	return MaskData{
+		FirstCharsToShow: r.FirstCharsToShow,
+		LastCharsToShow:  r.LastCharsToShow,
+		RestrictionType:  r.RestrictionType,
+		CharsToIgnore:    []rune(r.CharsToIgnore),
+		JSONKeys:         keys,
+		KeepSameLength:   r.KeepSameLength,
+	}
+}
+
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package logger_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+)
+
+const testPolicyYAML = `
+partners:
+  acme:
+    masking:
+      mask_char: "#"
+      replacement: "[hidden]"
+      rules:
+        - action: mask
+          json_paths: ["passengers.[].email"]
+          xml_tags: [Email]
+          form_keys: [email]
+          query_params: [email]
+          headers: [X-Customer-Email]
+          first_chars_to_show: 2
+          last_chars_to_show: 4
+          keep_same_length: true
+          chars_to_ignore: "@"
+        - action: redact
+          json_paths: [payment.card_number]
+          xml_tags: [CardNumber]
+          form_keys: [card_number]
+          query_params: [api_key]
+          headers: [x-api-key]
+`
+
+func loadTestPolicy(t *testing.T) *logger.MaskingPolicy {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	assert.NoError(t, v.ReadConfig(strings.NewReader(testPolicyYAML)))
+
+	policy, err := logger.LoadMaskingPolicy(v, "partners.acme.masking")
+	assert.NoError(t, err)
+	return policy
+}
+
+func Test_LoadMaskingPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := loadTestPolicy(t)
+	if assert.Len(policy.Rules, 2) {
+		assert.Equal(logger.MaskingActionMask, policy.Rules[0].Action)
+		assert.Equal([]string{"passengers.[].email"}, policy.Rules[0].JSONPaths)
+		assert.Equal(2, policy.Rules[0].FirstCharsToShow)
+		assert.Equal("@", policy.Rules[0].CharsToIgnore)
+	}
+
+	_, err := logger.LoadMaskingPolicy(viper.New(), "partners.unknown")
+	assert.Error(err)
+
+	v := viper.New()
+	v.Set("masking.rules", []map[string]any{{"action": "hide"}})
+	_, err = logger.LoadMaskingPolicy(v, "masking")
+	assert.Error(err)
+}
+
+func Test_MaskingPolicy_Apply(t *testing.T) {
+	policy := loadTestPolicy(t)
+
+	testCases := map[string]struct {
+		contentType string
+		body        string
+		expected    string
+	}{
+		"json": {
+			contentType: "application/json; charset=utf-8",
+			body:        `{"passengers":[{"email":"someone@wego.com"}],"payment":{"card_number":"4111111111111111"}}`,
+			expected:    `{"passengers":[{"email":"so#####@####.com"}],"payment":{"card_number":"[hidden]"}}`,
+		},
+		"xml": {
+			contentType: "text/xml",
+			body:        `<Booking><Email>someone@wego.com</Email><CardNumber>4111111111111111</CardNumber></Booking>`,
+			expected:    `<Booking><Email>so#####@####.com</Email><CardNumber>[hidden]</CardNumber></Booking>`,
+		},
+		"form": {
+			contentType: "application/x-www-form-urlencoded",
+			body:        `card_number=4111111111111111&email=someone%40wego.com`,
+			expected:    `card_number=%5Bhidden%5D&email=so%23%23%23%23%23%40%23%23%23%23.com`,
+		},
+		"unknown content type": {
+			contentType: "text/plain",
+			body:        `4111111111111111`,
+			expected:    `4111111111111111`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, policy.Apply(tc.contentType, tc.body))
+		})
+	}
+}
+
+func Test_MaskingPolicy_ApplyQueryAndHeaders(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := loadTestPolicy(t)
+	assert.Equal("api_key=%5Bhidden%5D&email=so%23%23%23%23%23%40%23%23%23%23.com",
+		policy.ApplyQuery("api_key=secret&email=someone%40wego.com"))
+
+	headers := logger.Headers{"X-Api-Key": "secret", "X-Customer-Email": "someone@wego.com", "Accept": "*/*"}
+	assert.Equal(logger.Headers{
+		"X-Api-Key":        "[hidden]",
+		"X-Customer-Email": "so#####@####.com",
+		"Accept":           "*/*",
+	}, policy.ApplyHeaders(headers))
+	assert.Equal("secret", headers["X-Api-Key"])
+
+	var nilPolicy *logger.MaskingPolicy
+	assert.Equal("body", nilPolicy.Apply("application/json", "body"))
+}
@@ -9,18 +9,37 @@ import (
 	"github.com/wego/pkg/errors"
 )
 
-// RedactXML replaces inner text of tags from the input XML with replacement or defaultReplacement when replacement is empty
+/*
+RedactXML replaces inner text of tags from the input XML with replacement or defaultReplacement when replacement is empty.
+
+A tag starting with `/` or `(` is a full XPath, e.g. //Payment[@type='card']/Number, which may select attributes too,
+e.g. //Card/@Number, other tags are matched anywhere like the tags of XMLTarget. Only the redacted text nodes &
+attribute values are replaced, see RedactXMLTargets.
+*/
 func RedactXML(xml, replacement string, tags []string) string {
-	replacement = replacementCharOrDefault(replacement)
-	doc, err := xmlquery.Parse(strings.NewReader(xml))
+	matcher := &xmlMatcher{}
+	var doc *xmlquery.Node
+	for _, tag := range tags {
+		if xpathOf(tag) != tag {
+			matcher.targets = append(matcher.targets, XMLTarget{Tag: tag})
+			continue
+		}
+
+		if doc == nil {
+			var err error
+			if doc, err = xmlquery.Parse(strings.NewReader(xml)); err != nil {
+				return errors.New("invalid XML input", err).Error()
+			}
+		}
+		if err := matcher.addXPath(doc, tag); err != nil {
+			return errors.New("invalid XPath", err).Error()
+		}
+	}
+
+	out, err := redactXMLBytes(xml, replacementCharOrDefault(replacement), matcher)
 	if err != nil {
 		return errors.New("invalid XML input", err).Error()
 	}
-	text := findTextMulti(doc, tags)
-	out := xml
-	for _, t := range text {
-		out = strings.ReplaceAll(out, t, replacement)
-	}
 	return out
 }
 
@@ -28,16 +47,26 @@ func RedactXML(xml, replacement string, tags []string) string {
 RedactJSON replaces value of key paths from the input JSON with replacement or defaultReplacement when replacement is empty.
 
 For nested arrays, use `[]` as the key.
+
+A single key starting with `$` is a JSONPath-style selector, see jsonSelector, e.g. `$..cardNumber` redacts the
+cardNumber values at any depth.
 */
 func RedactJSON(json, replacement string, keys [][]string) string {
 	replacement = replacementCharOrDefault(replacement)
-	replacementValue := fastjson.MustParse(`"` + replacement + `"`)
+	replacementValue := jsonString(replacement)
 	var p fastjson.Parser
 	root, err := p.Parse(json)
 	if err != nil {
 		return err.Error()
 	}
 	for _, toRedact := range keys {
+		if isJSONSelector(toRedact) {
+			if err := redactSelector(root, toRedact[0], replacementValue); err != nil {
+				return errors.New("invalid JSON selector", err).Error()
+			}
+			continue
+		}
+
 		l := len(toRedact)
 		switch {
 		case l == 1:
@@ -106,26 +135,6 @@ func redactArrayRecursive(obj *fastjson.Value, keys []string, replacementValue *
 	}
 }
 
-func findText(doc *xmlquery.Node, tag string) []string {
-	text := []string{}
-	nodes := xmlquery.Find(doc, "//"+tag)
-	for _, node := range nodes {
-		it := node.InnerText()
-		if strings.TrimSpace(it) != "" {
-			text = append(text, node.InnerText())
-		}
-	}
-	return text
-}
-
-func findTextMulti(doc *xmlquery.Node, tags []string) []string {
-	text := []string{}
-	for _, tag := range tags {
-		text = append(text, findText(doc, tag)...)
-	}
-	return text
-}
-
 /*
 RedactFormURLEncoded replaces value of keys from the input form encoded string with replacement or defaultReplacement when replacement is empty.
 
@@ -2,7 +2,6 @@ package logger
 
 import (
 	"encoding/json"
-	"strings"
 	"time"
 
 	"github.com/wego/pkg/common"
@@ -34,6 +33,8 @@ type Request struct {
 	RequestedAt     time.Time
 	Duration        time.Duration
 	Error           error
+	// trace is the span of the context the request is logged with
+	trace traceContext
 }
 
 // SetBasics set the basics value
@@ -113,24 +114,18 @@ func (r *Request) fields() []zapcore.Field {
 	if r.Error != nil {
 		fields = append(fields, zap.String("error", r.Error.Error()))
 	}
+	fields = append(fields, r.trace.fields()...)
 
 	return fields
 }
 
 // MarshalLogArray marshal Headers to zap log array
-// Need to implement this to log it with zap.Array
+// Need to implement this to log it with zap.Array, the sensitive headers are masked, see SetSensitiveHeaders
 func (h Headers) MarshalLogArray(enc zapcore.ArrayEncoder) error {
 	for k, v := range h {
-		if sensitive := sensitiveHeaders[strings.ToLower(k)]; sensitive {
-			if strings.ToLower(k) == sensitiveHeaderAuthorization {
-				v = maskAuthorizationHeader(v)
-			} else {
-				v = defaultReplacement
-			}
-		}
 		err := enc.AppendObject(header{
 			name:  k,
-			value: v,
+			value: MaskHeader(k, v),
 		})
 		if err != nil {
 			return err
@@ -139,22 +134,6 @@ func (h Headers) MarshalLogArray(enc zapcore.ArrayEncoder) error {
 	return nil
 }
 
-func maskAuthorizationHeader(value string) string {
-	maskChar := "***"
-	maskData := MaskData{
-		FirstCharsToShow: 2,
-		LastCharsToShow:  3,
-		KeepSameLength:   false,
-		prefixesToSkip:   []string{"pk_test_", "sk_test_", "pk_", "sk_"},
-	}
-
-	if authType, credentials, found := strings.Cut(value, " "); found {
-		return authType + " " + getMaskedValue(maskChar, credentials, maskData)
-	}
-
-	return getMaskedValue(maskChar, value, maskData)
-}
-
 // MarshalLogObject marshal header to zap log object
 // The struct need to implement this, so we can log it as object
 func (h header) MarshalLogObject(enc zapcore.ObjectEncoder) error {
@@ -8,7 +8,7 @@ import (
 	"github.com/wego/pkg/errors"
 )
 
-func Test_maskAuthorizationHeader(t *testing.T) {
+func Test_maskCredentials(t *testing.T) {
 	testCases := []struct {
 		name  string
 		value string
@@ -59,7 +59,7 @@ func Test_maskAuthorizationHeader(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			assert := assert.New(t)
-			got := maskAuthorizationHeader(tc.value)
+			got := maskCredentials(tc.value, []string{"pk_test_", "sk_test_", "pk_", "sk_"})
 
 			assert.Equal(tc.want, got)
 		})
@@ -0,0 +1,298 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fastjson"
+)
+
+const selectorRoot = "$"
+
+// selectorSegment is a step of a JSON selector
+type selectorSegment struct {
+	// descendant matches the children of the current values & all their descendants, i.e. `..`
+	descendant bool
+	wildcard   bool
+	name       string
+	pattern    *regexp.Regexp
+	index      int
+	hasIndex   bool
+}
+
+/*
+jsonSelector is a JSONPath-style selector of the values in a JSON document, supporting:
+  - `$` the root
+  - `.name` or `['name']` a child by name
+  - `..name` the descendants by name, e.g. `$..cardNumber`
+  - `.*` or `[*]` all the children, e.g. `$.passengers[*].documents[*].number`
+  - `[n]` an array item by index
+  - `./regex/` or `../regex/` the children or descendants whose names match the regex, e.g. `$../(?i)^card_?number$/`
+*/
+type jsonSelector []selectorSegment
+
+// jsonTarget is a value matched by a selector, as the key or index of it in its parent
+type jsonTarget struct {
+	parent *fastjson.Value
+	key    string
+	index  int
+}
+
+// isJSONSelector reports whether the JSON keys of masking are a selector instead of a key path
+func isJSONSelector(keys []string) bool {
+	return len(keys) == 1 && strings.HasPrefix(keys[0], selectorRoot)
+}
+
+func parseJSONSelector(s string) (jsonSelector, error) {
+	if !strings.HasPrefix(s, selectorRoot) {
+		return nil, fmt.Errorf("selector must start with %s: %s", selectorRoot, s)
+	}
+
+	var sel jsonSelector
+	rest := s[len(selectorRoot):]
+	for rest != "" {
+		var seg selectorSegment
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			seg.descendant = true
+			rest = rest[2:]
+		case rest[0] == '.':
+			rest = rest[1:]
+		case rest[0] == '[':
+		default:
+			return nil, fmt.Errorf("unexpected %q in selector: %s", rest[0], s)
+		}
+
+		var err error
+		if rest, err = parseSegment(rest, &seg); err != nil {
+			return nil, fmt.Errorf("%w in selector: %s", err, s)
+		}
+		sel = append(sel, seg)
+	}
+	return sel, nil
+}
+
+// parseSegment parses the segment at the start of s into seg & returns the rest of s
+func parseSegment(s string, seg *selectorSegment) (string, error) {
+	switch {
+	case s == "":
+		return "", fmt.Errorf("missing name")
+	case s[0] == '*':
+		seg.wildcard = true
+		return s[1:], nil
+	case s[0] == '/':
+		end := closingSlash(s)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated regex")
+		}
+		pattern, err := regexp.Compile(strings.ReplaceAll(s[1:end], `\/`, "/"))
+		if err != nil {
+			return "", err
+		}
+		seg.pattern = pattern
+		return s[end+1:], nil
+	case s[0] == '[':
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated bracket")
+		}
+		return s[end+1:], parseBracket(s[1:end], seg)
+	default:
+		end := strings.IndexAny(s, ".[")
+		if end < 0 {
+			end = len(s)
+		}
+		if end == 0 {
+			return "", fmt.Errorf("missing name")
+		}
+		seg.name = s[:end]
+		return s[end:], nil
+	}
+}
+
+func parseBracket(s string, seg *selectorSegment) error {
+	switch {
+	case s == "*":
+		seg.wildcard = true
+	case len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0]:
+		seg.name = s[1 : len(s)-1]
+	default:
+		index, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid index %q", s)
+		}
+		seg.index = index
+		seg.hasIndex = true
+	}
+	return nil
+}
+
+// closingSlash returns the index of the slash closing the regex at the start of s, escaped slashes are skipped
+func closingSlash(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '/':
+			return i
+		}
+	}
+	return -1
+}
+
+// targets returns the values matched by the selector, each value is returned once
+func (sel jsonSelector) targets(root *fastjson.Value) []jsonTarget {
+	current := []*fastjson.Value{root}
+	var matched []jsonTarget
+	for _, seg := range sel {
+		var values []*fastjson.Value
+		if seg.descendant {
+			for _, v := range current {
+				values = appendDescendants(values, v)
+			}
+		} else {
+			values = current
+		}
+
+		matched = matched[:0]
+		seen := map[jsonTarget]bool{}
+		for _, v := range values {
+			for _, target := range seg.children(v) {
+				if !seen[target] {
+					seen[target] = true
+					matched = append(matched, target)
+				}
+			}
+		}
+
+		current = current[:0:0]
+		for _, target := range matched {
+			current = append(current, target.value())
+		}
+	}
+	return matched
+}
+
+// children returns the children of the value matched by the segment
+func (seg selectorSegment) children(v *fastjson.Value) (res []jsonTarget) {
+	// Type unescapes the raw strings, which are kept raw unless masked, so it's not used to walk the values
+	if obj := v.GetObject(); obj != nil {
+		obj.Visit(func(key []byte, _ *fastjson.Value) {
+			if step := (pathStep{key: string(key), index: -1}); seg.matchesStep(step) {
+				res = append(res, jsonTarget{parent: v, key: step.key, index: -1})
+			}
+		})
+		return
+	}
+
+	items := v.GetArray()
+	switch {
+	case seg.wildcard:
+		for i := range items {
+			res = append(res, jsonTarget{parent: v, index: i})
+		}
+	case seg.hasIndex && seg.index >= 0 && seg.index < len(items):
+		res = append(res, jsonTarget{parent: v, index: seg.index})
+	}
+	return
+}
+
+// appendDescendants appends the value & all the objects & arrays nested in it
+func appendDescendants(values []*fastjson.Value, v *fastjson.Value) []*fastjson.Value {
+	values = append(values, v)
+	if obj := v.GetObject(); obj != nil {
+		obj.Visit(func(_ []byte, child *fastjson.Value) {
+			values = appendDescendants(values, child)
+		})
+		return values
+	}
+	for _, child := range v.GetArray() {
+		values = appendDescendants(values, child)
+	}
+	return values
+}
+
+func (t jsonTarget) value() *fastjson.Value {
+	if t.index >= 0 {
+		return t.parent.GetArray()[t.index]
+	}
+	return t.parent.Get(t.key)
+}
+
+func (t jsonTarget) set(value *fastjson.Value) {
+	if t.index >= 0 {
+		t.parent.SetArrayItem(t.index, value)
+		return
+	}
+	t.parent.Set(t.key, value)
+}
+
+// maskSelector masks the string values matched by the selector
+func maskSelector(root *fastjson.Value, selector, maskChar string, toMask MaskData) error {
+	sel, err := parseJSONSelector(selector)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range sel.targets(root) {
+		if value := getJSONValue(target.value()); value != "" {
+			target.set(jsonString(getMaskedValue(maskChar, value, toMask)))
+		}
+	}
+	return nil
+}
+
+// redactSelector replaces the values matched by the selector, including objects & arrays, except nulls
+func redactSelector(root *fastjson.Value, selector string, replacementValue *fastjson.Value) error {
+	sel, err := parseJSONSelector(selector)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range sel.targets(root) {
+		if value := target.value(); value != nil && value.Type() != fastjson.TypeNull {
+			target.set(replacementValue)
+		}
+	}
+	return nil
+}
+
+// pathStep is a step of the path of a value in a JSON document, the key of it in its parent object, or the index of
+// it in its parent array when index >= 0
+type pathStep struct {
+	key   string
+	index int
+}
+
+// matchesPath reports whether the value at the path is matched by the selector
+func (sel jsonSelector) matchesPath(path []pathStep) bool {
+	if len(sel) == 0 {
+		return len(path) == 0
+	}
+
+	seg := sel[0]
+	if !seg.descendant {
+		return len(path) > 0 && seg.matchesStep(path[0]) && sel[1:].matchesPath(path[1:])
+	}
+	for i := range path {
+		if seg.matchesStep(path[i]) && sel[1:].matchesPath(path[i+1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (seg selectorSegment) matchesStep(step pathStep) bool {
+	if step.index >= 0 {
+		return seg.wildcard || (seg.hasIndex && seg.index == step.index)
+	}
+	if seg.hasIndex {
+		return false
+	}
+	if seg.pattern != nil {
+		return seg.pattern.MatchString(step.key)
+	}
+	return seg.wildcard || seg.name == step.key
+}
@@ -0,0 +1,180 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+)
+
+const selectorTestJSON = `{"payment":{"cardNumber":"4111111111111111","holder":{"cardNumber":"5500000000000004"}},` +
+	`"passengers":[{"name":"john","documents":[{"number":"A1234567"},{"number":"B7654321"}]},` +
+	`{"name":"jane","documents":[{"number":"C1111111"}]}],"card_number":"378282246310005","cvv":123,"note":null}`
+
+func Test_MaskJSON_Selectors(t *testing.T) {
+	tests := map[string]struct {
+		selector string
+		expected string
+	}{
+		"recursive descent": {
+			selector: "$..cardNumber",
+			expected: `{"payment":{"cardNumber":"41**********1111","holder":{"cardNumber":"55**********0004"}},` +
+				`"passengers":[{"name":"john","documents":[{"number":"A1234567"},{"number":"B7654321"}]},` +
+				`{"name":"jane","documents":[{"number":"C1111111"}]}],"card_number":"378282246310005","cvv":123,"note":null}`,
+		},
+		"wildcards": {
+			selector: "$.passengers[*].documents[*].number",
+			expected: `{"payment":{"cardNumber":"4111111111111111","holder":{"cardNumber":"5500000000000004"}},` +
+				`"passengers":[{"name":"john","documents":[{"number":"A1**4567"},{"number":"B7**4321"}]},` +
+				`{"name":"jane","documents":[{"number":"C1**1111"}]}],"card_number":"378282246310005","cvv":123,"note":null}`,
+		},
+		"index & quoted name": {
+			selector: "$.passengers[1]['documents'][0].number",
+			expected: `{"payment":{"cardNumber":"4111111111111111","holder":{"cardNumber":"5500000000000004"}},` +
+				`"passengers":[{"name":"john","documents":[{"number":"A1234567"},{"number":"B7654321"}]},` +
+				`{"name":"jane","documents":[{"number":"C1**1111"}]}],"card_number":"378282246310005","cvv":123,"note":null}`,
+		},
+		"key name regex": {
+			selector: "$../(?i)^card_?number$/",
+			expected: `{"payment":{"cardNumber":"41**********1111","holder":{"cardNumber":"55**********0004"}},` +
+				`"passengers":[{"name":"john","documents":[{"number":"A1234567"},{"number":"B7654321"}]},` +
+				`{"name":"jane","documents":[{"number":"C1111111"}]}],"card_number":"37*********0005","cvv":123,"note":null}`,
+		},
+		"non-string values are not masked": {
+			selector: "$.cvv",
+			expected: selectorTestJSON,
+		},
+		"not found": {
+			selector: "$..expiry",
+			expected: selectorTestJSON,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			output := logger.MaskJSON(selectorTestJSON, "*", []logger.MaskData{
+				{JSONKeys: []string{test.selector}, FirstCharsToShow: 2, LastCharsToShow: 4, KeepSameLength: true},
+			})
+			assert.JSONEq(test.expected, output)
+		})
+	}
+}
+
+func Test_MaskJSON_EscapedValues(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		keys     []string
+		expected string
+	}{
+		"selector": {
+			input:    `{"a":"x\"yzzzzzzz"}`,
+			keys:     []string{"$..a"},
+			expected: `{"a":"x\"*****zzz"}`,
+		},
+		"key path": {
+			input:    `{"a":{"b":"x\\yzzzzzzz"}}`,
+			keys:     []string{"a", "b"},
+			expected: `{"a":{"b":"x\\*****zzz"}}`,
+		},
+		"array": {
+			input:    `{"a":["\"\"\"zzzzzz"]}`,
+			keys:     []string{"a", "[]"},
+			expected: `{"a":["\"\"****zzz"]}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			var output string
+			assert.NotPanics(func() {
+				output = logger.MaskJSON(test.input, "*", []logger.MaskData{
+					{JSONKeys: test.keys, FirstCharsToShow: 2, LastCharsToShow: 3, KeepSameLength: true},
+				})
+			})
+			assert.Equal(test.expected, output)
+		})
+	}
+}
+
+func Test_RedactJSON_EscapedReplacement(t *testing.T) {
+	assert := assert.New(t)
+
+	var output string
+	assert.NotPanics(func() {
+		output = logger.RedactJSON(`{"a":"x\"y"}`, `[filtered "a"]`, [][]string{{"$..a"}})
+	})
+	assert.Equal(`{"a":"[filtered \"a\"]"}`, output)
+}
+
+func Test_MaskJSON_InvalidSelector(t *testing.T) {
+	for _, selector := range []string{"$.", "$[abc]", "$../[/", "$.a[0", "$x"} {
+		t.Run(selector, func(t *testing.T) {
+			assert := assert.New(t)
+			output := logger.MaskJSON(selectorTestJSON, "*", []logger.MaskData{{JSONKeys: []string{selector}}})
+			assert.Contains(output, "invalid JSON selector")
+		})
+	}
+}
+
+func Test_RedactJSON_Selectors(t *testing.T) {
+	tests := map[string]struct {
+		selector string
+		expected string
+	}{
+		"recursive descent": {
+			selector: "$..number",
+			expected: `{"payment":{"cardNumber":"4111111111111111","holder":{"cardNumber":"5500000000000004"}},` +
+				`"passengers":[{"name":"john","documents":[{"number":"[Filtered]"},{"number":"[Filtered]"}]},` +
+				`{"name":"jane","documents":[{"number":"[Filtered]"}]}],"card_number":"378282246310005","cvv":123,"note":null}`,
+		},
+		"objects & non-string values": {
+			selector: "$../^(holder|cvv|note)$/",
+			expected: `{"payment":{"cardNumber":"4111111111111111","holder":"[Filtered]"},` +
+				`"passengers":[{"name":"john","documents":[{"number":"A1234567"},{"number":"B7654321"}]},` +
+				`{"name":"jane","documents":[{"number":"C1111111"}]}],"card_number":"378282246310005","cvv":"[Filtered]","note":null}`,
+		},
+		"wildcard of object": {
+			selector: "$.payment.*",
+			expected: `{"payment":{"cardNumber":"[Filtered]","holder":"[Filtered]"},` +
+				`"passengers":[{"name":"john","documents":[{"number":"A1234567"},{"number":"B7654321"}]},` +
+				`{"name":"jane","documents":[{"number":"C1111111"}]}],"card_number":"378282246310005","cvv":123,"note":null}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			output := logger.RedactJSON(selectorTestJSON, "[Filtered]", [][]string{{test.selector}})
+			assert.JSONEq(test.expected, output)
+		})
+	}
+}
+
+const xpathTestXML = `<Booking><Payment type="card"><Number>4111111111111111</Number></Payment>` +
+	`<Payment type="voucher"><Number>VOUCHER123</Number></Payment></Booking>`
+
+func Test_MaskXML_XPath(t *testing.T) {
+	assert := assert.New(t)
+
+	output := logger.MaskXML(xpathTestXML, "*", []logger.MaskData{
+		{XMLTag: "//Payment[@type='card']/Number", FirstCharsToShow: 4, LastCharsToShow: 4, KeepSameLength: true},
+	})
+	assert.Equal(`<Booking><Payment type="card"><Number>4111********1111</Number></Payment>`+
+		`<Payment type="voucher"><Number>VOUCHER123</Number></Payment></Booking>`, output)
+
+	output = logger.MaskXML(xpathTestXML, "*", []logger.MaskData{{XMLTag: "//Payment[@type="}})
+	assert.Contains(output, "invalid XPath")
+}
+
+func Test_RedactXML_XPath(t *testing.T) {
+	assert := assert.New(t)
+
+	output := logger.RedactXML(xpathTestXML, "[Filtered]", []string{"//Payment[@type='card']/Number"})
+	assert.Equal(`<Booking><Payment type="card"><Number>[Filtered]</Number></Payment>`+
+		`<Payment type="voucher"><Number>VOUCHER123</Number></Payment></Booking>`, output)
+
+	output = logger.RedactXML(xpathTestXML, "[Filtered]", []string{"//Payment[@type="})
+	assert.Contains(output, "invalid XPath")
+}
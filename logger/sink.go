@@ -0,0 +1,217 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// SinkType is where the logs of a log type are written to
+type SinkType string
+
+// sink types
+const (
+	SinkFile   SinkType = "file"
+	SinkStdout SinkType = "stdout"
+	// SinkOTLP exports the logs to an OpenTelemetry collector, see OTLPConfig. The logs are exported in batches by a
+	// background goroutine, Sync waits for the exports.
+	SinkOTLP SinkType = "otlp"
+)
+
+// Sink configures where & how the logs of a log type are written
+type Sink struct {
+	// Type is the type of the sink, SinkFile is used when it's empty. It's ignored when WriteSyncer is set.
+	Type SinkType
+	// WriteSyncer is a custom destination of the logs, it takes precedence over the other options
+	WriteSyncer zapcore.WriteSyncer
+	// Path is the path of the log file, {{env}} is replaced by the current environment.
+	// The default file of the log type under ./log is used when it's empty.
+	Path string
+	// Truncate truncates the log file on Init, the logs are appended to the existing file by default
+	Truncate bool
+	// Rotation configures the rotation of the log file, the file is not rotated when it's nil
+	Rotation *Rotation
+	// OTLP configures the export of the logs of SinkOTLP
+	OTLP *OTLPConfig
+}
+
+// Rotation configures the rotation of a log file, the rotated files are named with the time of the rotation
+type Rotation struct {
+	// MaxSizeMB is the size in megabytes to rotate the file at, 100 is used when it's 0
+	MaxSizeMB int
+	// Every rotates the file at the interval regardless of its size, it's disabled when it's 0
+	Every time.Duration
+	// MaxAge is the age to remove the rotated files at, they are kept forever when it's 0
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files to keep, all of them are kept when it's 0
+	MaxBackups int
+	// Compress compresses the rotated files with gzip
+	Compress bool
+}
+
+// Config configures the sink of each log type, the zero value appends to the default files under ./log
+type Config struct {
+	UltronEx       Sink
+	PartnerRequest Sink
+	Request        Sink
+	PromoCodeEvent Sink
+	// Events are the sinks of the event logs by name, see NewEventLog. The events are appended to
+	// ./log/{name}_events.{{env}}.log when their name has no sink.
+	Events map[string]Sink
+	// App is the sink of the application logs of FromContext, they are written to stdout when it's the zero value
+	App Sink
+	// AppLevel is the initial level of the application logs, it can be changed by SetLevel or LevelHandler
+	AppLevel zapcore.Level
+}
+
+// rotators stops the time-based rotations started by the last Init
+var (
+	rotatorsMu sync.Mutex
+	rotators   []chan struct{}
+)
+
+// files are the log files opened since the last Init, which are closed by the next Init
+var (
+	filesMu sync.Mutex
+	files   []io.Closer
+)
+
+func (s Sink) writeSyncer(defaultFileName string) (zapcore.WriteSyncer, error) {
+	if s.WriteSyncer != nil {
+		return s.WriteSyncer, nil
+	}
+
+	if s.Type == SinkStdout {
+		return zapcore.Lock(os.Stdout), nil
+	}
+
+	if s.Type == SinkOTLP {
+		w, err := newOTLPWriter(s.OTLP, defaultFileName)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+
+	path := s.Path
+	if path == "" {
+		path = filepath.Join(logDir, defaultFileName)
+	}
+	path = strings.Replace(path, "{{env}}", viper.GetString("env"), 1)
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if s.Truncate {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Rotation == nil {
+		addFile(file)
+		return zapcore.Lock(file), nil
+	}
+
+	// lumberjack reopens the file itself
+	if err = file.Close(); err != nil {
+		return nil, err
+	}
+	return s.Rotation.writeSyncer(path), nil
+}
+
+func (r *Rotation) writeSyncer(path string) zapcore.WriteSyncer {
+	l := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    r.MaxSizeMB,
+		MaxAge:     int(r.MaxAge.Hours() / 24),
+		MaxBackups: r.MaxBackups,
+		Compress:   r.Compress,
+	}
+	if r.MaxAge > 0 && l.MaxAge == 0 {
+		// lumberjack counts the age in days
+		l.MaxAge = 1
+	}
+	addFile(l)
+
+	if r.Every > 0 {
+		done := make(chan struct{})
+		rotatorsMu.Lock()
+		rotators = append(rotators, done)
+		rotatorsMu.Unlock()
+
+		go rotate(l, r.Every, done)
+	}
+	return zapcore.AddSync(l)
+}
+
+func rotate(l *lumberjack.Logger, every time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = l.Rotate()
+		case <-done:
+			return
+		}
+	}
+}
+
+// stopRotators stops the time-based rotations of the previous Init
+func stopRotators() {
+	rotatorsMu.Lock()
+	defer rotatorsMu.Unlock()
+
+	for _, done := range rotators {
+		close(done)
+	}
+	rotators = nil
+}
+
+func addFile(f io.Closer) {
+	filesMu.Lock()
+	defer filesMu.Unlock()
+
+	files = append(files, f)
+}
+
+// closeSinks stops the time-based rotations & the OTLP writers, then closes the files opened since the last Init
+func closeSinks() {
+	stopRotators()
+	stopOTLPWriters()
+
+	filesMu.Lock()
+	closing := files
+	files = nil
+	filesMu.Unlock()
+
+	for _, f := range closing {
+		_ = f.Close()
+	}
+}
+
+func newLogger(ws zapcore.WriteSyncer) *zap.Logger {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	// remove unwanted keys
+	encoderConfig.MessageKey = ""
+	encoderConfig.LevelKey = ""
+	encoderConfig.CallerKey = ""
+	encoderConfig.TimeKey = ""
+
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), ws, zap.InfoLevel)
+	return zap.New(core)
+}
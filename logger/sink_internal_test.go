@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Init_ClosesSinks(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	conf := Config{
+		UltronEx:       Sink{Path: filepath.Join(dir, "ultronex.log")},
+		PartnerRequest: Sink{Path: filepath.Join(dir, "partner_requests.log"), Rotation: &Rotation{Every: time.Hour}},
+		Request:        Sink{Path: filepath.Join(dir, "requests.log")},
+		PromoCodeEvent: Sink{Path: filepath.Join(dir, "promocode_events.log")},
+	}
+	assert.NoError(Init(conf))
+	previous := files
+	assert.Len(previous, 4, "the 3 files & the rotated file")
+	assert.Len(rotators, 1)
+
+	assert.NoError(Init(conf))
+	for _, f := range previous {
+		if file, ok := f.(*os.File); ok {
+			_, err := file.WriteString("{}\n")
+			assert.ErrorIs(err, os.ErrClosed, "the files of the previous Init are closed")
+		}
+	}
+	previous = files
+
+	conf.App = Sink{Type: SinkOTLP}
+	assert.Error(Init(conf), "the OTLP sink has no endpoint")
+	assert.Empty(files, "the sinks already built are closed")
+	assert.Empty(rotators)
+	for _, f := range previous {
+		if file, ok := f.(*os.File); ok {
+			_, err := file.WriteString("{}\n")
+			assert.ErrorIs(err, os.ErrClosed)
+		}
+	}
+	assert.Empty(loggers, "the logs are dropped")
+	assert.Nil(appLogger.Load())
+	LogRequest(&Request{Type: "RequestType"})
+}
@@ -0,0 +1,85 @@
+package logger_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+func Test_Init_WriteSyncer(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	dir := t.TempDir()
+	err := logger.Init(logger.Config{
+		UltronEx:       logger.Sink{Path: filepath.Join(dir, "ultronex.log")},
+		PartnerRequest: logger.Sink{WriteSyncer: zapcore.AddSync(&buf)},
+		Request:        logger.Sink{Path: filepath.Join(dir, "requests.log")},
+		PromoCodeEvent: logger.Sink{Path: filepath.Join(dir, "promocode_events.log")},
+	})
+	assert.NoError(err)
+
+	logger.LogPartnerRequest(&logger.Request{Type: requestType, URL: "https://wego.com"})
+	logger.Sync()
+	assert.Contains(buf.String(), `"url":"https://wego.com"`)
+}
+
+func Test_Init_AppendOrTruncate(t *testing.T) {
+	assert := assert.New(t)
+
+	viper.Set("env", "test")
+	dir := t.TempDir()
+	appendPath := filepath.Join(dir, "promocode_events.{{env}}.log")
+	truncatePath := filepath.Join(dir, "requests.log")
+	assert.NoError(os.WriteFile(strings.Replace(appendPath, "{{env}}", "test", 1), []byte("previous\n"), 0o644))
+	assert.NoError(os.WriteFile(truncatePath, []byte("previous\n"), 0o644))
+
+	err := logger.Init(logger.Config{
+		UltronEx:       logger.Sink{Type: logger.SinkStdout},
+		PartnerRequest: logger.Sink{Type: logger.SinkStdout},
+		Request:        logger.Sink{Path: truncatePath, Truncate: true},
+		PromoCodeEvent: logger.Sink{Path: appendPath},
+	})
+	assert.NoError(err)
+
+	logger.LogPromoCodeEvent(map[string]any{"code": "WEGO"})
+	logger.LogRequest(&logger.Request{Type: requestType})
+	logger.Sync()
+
+	content, err := os.ReadFile(filepath.Join(dir, "promocode_events.test.log"))
+	assert.NoError(err)
+	assert.Equal("previous\n{\"code\":\"WEGO\"}\n", string(content))
+
+	content, err = os.ReadFile(truncatePath)
+	assert.NoError(err)
+	assert.NotContains(string(content), "previous")
+	assert.Contains(string(content), `"type":"RequestType"`)
+}
+
+func Test_Init_Rotation(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "partner_requests.log")
+	err := logger.Init(logger.Config{
+		UltronEx:       logger.Sink{Type: logger.SinkStdout},
+		PartnerRequest: logger.Sink{Path: path, Rotation: &logger.Rotation{MaxSizeMB: 1, MaxBackups: 2}},
+		Request:        logger.Sink{Type: logger.SinkStdout},
+		PromoCodeEvent: logger.Sink{Type: logger.SinkStdout},
+	})
+	assert.NoError(err)
+
+	logger.LogPartnerRequest(&logger.Request{Type: requestType})
+	logger.Sync()
+
+	content, err := os.ReadFile(path)
+	assert.NoError(err)
+	assert.Contains(string(content), `"type":"RequestType"`)
+}
@@ -0,0 +1,471 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/wego/pkg/errors"
+)
+
+/*
+MaskJSONStream masks the JSON read from r like MaskJSON & writes it to w, without loading the whole document in memory,
+which suits large bodies. The output is the same as the one of MaskJSON, the values which are not masked are written
+as they are read, including their escapes, while the keys & the masked values are escaped the way fastjson does.
+
+When the input is invalid, the output masked so far is written with a truncated marker & the error is returned, the
+rest of the input is dropped.
+*/
+func MaskJSONStream(w io.Writer, r io.Reader, maskChar string, toMasks []MaskData) error {
+	m := &jsonStreamMasker{
+		w:         bufio.NewWriter(w),
+		maskChar:  maskCharOrDefault(maskChar),
+		toMasks:   toMasks,
+		selectors: make([]jsonSelector, len(toMasks)),
+	}
+	for i, toMask := range toMasks {
+		if !isJSONSelector(toMask.JSONKeys) {
+			continue
+		}
+
+		sel, err := parseJSONSelector(toMask.JSONKeys[0])
+		if err != nil {
+			return errors.New("invalid JSON selector", err)
+		}
+		m.selectors[i] = sel
+	}
+
+	m.raw = &rawReader{r: r}
+	dec := json.NewDecoder(m.raw)
+	dec.UseNumber()
+	err := m.mask(dec)
+	if err != nil {
+		_, _ = m.w.WriteString(truncatedMarker)
+		err = errors.New("invalid JSON input", err)
+	}
+	if flushErr := m.w.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// jsonFrame is an object or array being streamed
+type jsonFrame struct {
+	object    bool
+	count     int
+	expectKey bool
+}
+
+type jsonStreamMasker struct {
+	w         *bufio.Writer
+	maskChar  string
+	toMasks   []MaskData
+	selectors []jsonSelector
+	frames    []jsonFrame
+	path      []pathStep
+	buf       []byte
+	raw       *rawReader
+}
+
+func (m *jsonStreamMasker) mask(dec *json.Decoder) error {
+	for {
+		start := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			if len(m.frames) > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		raw := m.raw.token(start, dec.InputOffset())
+
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				m.beginValue()
+				_ = m.w.WriteByte(byte(delim))
+				m.frames = append(m.frames, jsonFrame{object: delim == '{', expectKey: delim == '{'})
+			default:
+				m.frames = m.frames[:len(m.frames)-1]
+				_ = m.w.WriteByte(byte(delim))
+				if m.endValue() {
+					return nil
+				}
+			}
+			continue
+		}
+
+		if top := m.top(); top != nil && top.expectKey {
+			key := tok.(string)
+			if top.count > 0 {
+				_ = m.w.WriteByte(',')
+			}
+			m.writeString(key)
+			_ = m.w.WriteByte(':')
+			m.path = append(m.path, pathStep{key: key, index: -1})
+			top.expectKey = false
+			continue
+		}
+
+		m.beginValue()
+		switch v := tok.(type) {
+		case string:
+			if masked, ok := m.maskValue(v); ok {
+				m.writeString(masked)
+			} else {
+				_, _ = m.w.Write(raw)
+			}
+		case json.Number:
+			_, _ = m.w.WriteString(v.String())
+		case bool:
+			_, _ = m.w.WriteString(strconv.FormatBool(v))
+		case nil:
+			_, _ = m.w.WriteString("null")
+		}
+		if m.endValue() {
+			return nil
+		}
+	}
+}
+
+func (m *jsonStreamMasker) top() *jsonFrame {
+	if len(m.frames) == 0 {
+		return nil
+	}
+	return &m.frames[len(m.frames)-1]
+}
+
+// beginValue writes the separator of a value in an array & steps into it
+func (m *jsonStreamMasker) beginValue() {
+	if top := m.top(); top != nil && !top.object {
+		if top.count > 0 {
+			_ = m.w.WriteByte(',')
+		}
+		m.path = append(m.path, pathStep{index: top.count})
+	}
+}
+
+// endValue steps out of a value, it reports whether the value is the root, which ends the document
+func (m *jsonStreamMasker) endValue() bool {
+	top := m.top()
+	if top == nil {
+		return true
+	}
+
+	m.path = m.path[:len(m.path)-1]
+	top.count++
+	top.expectKey = top.object
+	return false
+}
+
+// maskValue masks a string value at the current path with each matching MaskData in order, like MaskJSON does.
+// It reports whether the value is masked.
+func (m *jsonStreamMasker) maskValue(value string) (string, bool) {
+	masked := false
+	for i, toMask := range m.toMasks {
+		if value == "" {
+			break
+		}
+
+		matched := false
+		if m.selectors[i] != nil {
+			matched = m.selectors[i].matchesPath(m.path)
+		} else {
+			matched = matchesKeys(toMask.JSONKeys, m.path)
+		}
+		if matched {
+			value = getMaskedValue(m.maskChar, value, toMask)
+			masked = true
+		}
+	}
+	return value, masked
+}
+
+// writeString writes a JSON string the way fastjson marshals it, so the output is the same as the one of MaskJSON
+func (m *jsonStreamMasker) writeString(s string) {
+	if !strings.ContainsAny(s, `"\`) && !hasControlChars(s) {
+		_ = m.w.WriteByte('"')
+		_, _ = m.w.WriteString(s)
+		_ = m.w.WriteByte('"')
+		return
+	}
+
+	m.buf = strconv.AppendQuote(m.buf[:0], s)
+	_, _ = m.w.Write(m.buf)
+}
+
+func hasControlChars(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 {
+			return true
+		}
+	}
+	return false
+}
+
+// rawReader keeps the bytes read by the decoder since the end of the previous token, so the raw text of a token can
+// be written as it's read
+type rawReader struct {
+	r   io.Reader
+	buf []byte
+	// offset is the input offset of the first byte of buf
+	offset int64
+}
+
+func (r *rawReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.buf = append(r.buf, p[:n]...)
+	return n, err
+}
+
+// token returns the raw text of the token read between the input offsets, without the whitespaces & the separators
+// before it, & drops the bytes before the end of it
+func (r *rawReader) token(start, end int64) []byte {
+	raw := bytes.TrimLeft(r.buf[start-r.offset:end-r.offset], " \t\r\n,:")
+	r.buf = r.buf[end-r.offset:]
+	r.offset = end
+	return raw
+}
+
+// until returns the raw text read up to the input offset, & drops it
+func (r *rawReader) until(end int64) string {
+	raw := string(r.buf[:end-r.offset])
+	r.buf = r.buf[end-r.offset:]
+	r.offset = end
+	return raw
+}
+
+// matchesKeys reports whether the value at the path is matched by the JSON keys of a MaskData, where `[]` or an index
+// matches the items of an array
+func matchesKeys(keys []string, path []pathStep) bool {
+	if len(keys) != len(path) {
+		return false
+	}
+
+	for i, step := range path {
+		switch {
+		case step.index >= 0:
+			if keys[i] != arrayKey && keys[i] != strconv.Itoa(step.index) {
+				return false
+			}
+		case keys[i] != step.key:
+			return false
+		}
+	}
+	return true
+}
+
+/*
+MaskXMLStream masks the XML read from r like MaskXML & writes it to w, without building the whole DOM, which suits
+large bodies. The output is the same as the one of MaskXML, only the tags of the elements are supported, not XPaths.
+
+When the input is invalid, the output masked so far is written with a truncated marker & the error is returned, the
+rest of the input, including the content of a tag being masked, is dropped.
+*/
+func MaskXMLStream(w io.Writer, r io.Reader, maskChar string, toMasks []MaskData) error {
+	for _, toMask := range toMasks {
+		if xpathOf(toMask.XMLTag) == toMask.XMLTag {
+			return errors.New("XPath is not supported by MaskXMLStream: " + toMask.XMLTag)
+		}
+	}
+
+	raw := &rawReader{r: r}
+	dec := xml.NewDecoder(raw)
+	// the input is kept in its encoding like MaskXML does, so the offsets are the ones of the input
+	dec.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+	m := &xmlStreamMasker{
+		w:        bufio.NewWriter(w),
+		maskChar: maskCharOrDefault(maskChar),
+		toMasks:  toMasks,
+		raw:      raw,
+	}
+
+	err := m.mask(dec)
+	if err != nil {
+		_, _ = m.w.WriteString(truncatedMarker)
+		err = errors.New("invalid XML input", err)
+	}
+	if flushErr := m.w.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+// xmlNode is a node of an element being masked, an element or another node as it's read
+type xmlNode struct {
+	el  *xmlElement
+	raw string
+	// text is the text of a text node or a CDATA section, which is part of the inner text of the element
+	text string
+}
+
+// xmlElement is an element being streamed
+type xmlElement struct {
+	name string
+	// startTag & endTag are the tags as they're read, endTag is empty for a self-closing tag
+	startTag, endTag string
+	// buffered tells the element is matched by a MaskData or is in an element matched by one, its content is buffered
+	// until the end of the outermost matched element, where the masks are applied on it. The start tag of the
+	// outermost matched element is written as it's read.
+	buffered bool
+	children []xmlNode
+}
+
+type xmlStreamMasker struct {
+	w        *bufio.Writer
+	maskChar string
+	toMasks  []MaskData
+	raw      *rawReader
+	elements []*xmlElement
+}
+
+func (m *xmlStreamMasker) mask(dec *xml.Decoder) error {
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			if len(m.elements) > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		raw := m.raw.until(dec.InputOffset())
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			m.startElement(tok, raw)
+		case xml.EndElement:
+			if err := m.endElement(tok, raw); err != nil {
+				return err
+			}
+		case xml.CharData:
+			m.write(xmlNode{raw: raw, text: string(tok)})
+		default:
+			m.write(xmlNode{raw: raw})
+		}
+	}
+}
+
+func (m *xmlStreamMasker) startElement(tok xml.StartElement, raw string) {
+	el := &xmlElement{name: qualifiedName(tok.Name), startTag: raw}
+	parent := m.parent()
+	if parent != nil {
+		el.buffered = parent.buffered
+	}
+	for _, toMask := range m.toMasks {
+		if toMask.XMLTag == el.name {
+			el.buffered = true
+		}
+	}
+
+	if parent != nil && parent.buffered {
+		parent.children = append(parent.children, xmlNode{el: el})
+	} else {
+		_, _ = m.w.WriteString(el.startTag)
+	}
+	m.elements = append(m.elements, el)
+}
+
+func (m *xmlStreamMasker) endElement(tok xml.EndElement, raw string) error {
+	el := m.parent()
+	if el == nil || el.name != qualifiedName(tok.Name) {
+		return errors.New("unexpected end element </" + qualifiedName(tok.Name) + ">")
+	}
+	m.elements = m.elements[:len(m.elements)-1]
+	el.endTag = raw
+
+	switch parent := m.parent(); {
+	case parent != nil && parent.buffered:
+	case el.buffered:
+		// the outermost matched element is masked the way MaskXML does, applying the masks in order
+		for _, toMask := range m.toMasks {
+			m.maskElement(el, toMask)
+		}
+		m.writeContent(el)
+		_, _ = m.w.WriteString(el.endTag)
+	default:
+		_, _ = m.w.WriteString(el.endTag)
+	}
+	return nil
+}
+
+// maskElement masks the elements of the tag of the MaskData like MaskXML does, where the content of an element is
+// replaced by its masked inner text
+func (m *xmlStreamMasker) maskElement(el *xmlElement, toMask MaskData) {
+	if el.name == toMask.XMLTag {
+		if innerText := el.innerText(); strings.TrimSpace(innerText) != "" {
+			masked := getMaskedValue(m.maskChar, innerText, toMask)
+			el.children = []xmlNode{{raw: html.EscapeString(masked), text: masked}}
+			return
+		}
+	}
+
+	for _, child := range el.children {
+		if child.el != nil {
+			m.maskElement(child.el, toMask)
+		}
+	}
+}
+
+func (el *xmlElement) innerText() string {
+	var innerText strings.Builder
+	var write func(el *xmlElement)
+	write = func(el *xmlElement) {
+		for _, child := range el.children {
+			if child.el != nil {
+				write(child.el)
+				continue
+			}
+			innerText.WriteString(child.text)
+		}
+	}
+	write(el)
+	return innerText.String()
+}
+
+func (m *xmlStreamMasker) parent() *xmlElement {
+	if len(m.elements) == 0 {
+		return nil
+	}
+	return m.elements[len(m.elements)-1]
+}
+
+// write writes a node, or buffers it when it's in an element being masked
+func (m *xmlStreamMasker) write(node xmlNode) {
+	el := m.parent()
+	if el != nil && el.buffered {
+		el.children = append(el.children, node)
+		return
+	}
+	_, _ = m.w.WriteString(node.raw)
+}
+
+// writeContent writes the buffered content of an element
+func (m *xmlStreamMasker) writeContent(el *xmlElement) {
+	for _, child := range el.children {
+		if child.el != nil {
+			_, _ = m.w.WriteString(child.el.startTag)
+			m.writeContent(child.el)
+			_, _ = m.w.WriteString(child.el.endTag)
+			continue
+		}
+		_, _ = m.w.WriteString(child.raw)
+	}
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
@@ -0,0 +1,437 @@
+package logger_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+)
+
+// the masks of the fixtures of MaskXML & MaskJSON, shared by the streaming tests
+var xmlMaskData = []logger.MaskData{
+	{
+		XMLTag:           "Test1",
+		FirstCharsToShow: 4,
+		LastCharsToShow:  6,
+		KeepSameLength:   true,
+	},
+	{
+		XMLTag:           "Number",
+		FirstCharsToShow: 4,
+		LastCharsToShow:  6,
+		KeepSameLength:   true,
+	},
+	{
+		XMLTag:           "Email",
+		FirstCharsToShow: 3,
+		LastCharsToShow:  5,
+		CharsToIgnore:    []rune{'@'},
+		KeepSameLength:   true,
+	},
+	{
+		XMLTag:           "SomethingThatDoesNotExists",
+		FirstCharsToShow: 2,
+		LastCharsToShow:  6,
+		KeepSameLength:   true,
+	},
+	{
+		XMLTag:           "AgentID",
+		FirstCharsToShow: 0,
+		LastCharsToShow:  0,
+		KeepSameLength:   true,
+	},
+	{
+		XMLTag:           "ClientID",
+		FirstCharsToShow: 2,
+		LastCharsToShow:  0,
+		KeepSameLength:   true,
+	},
+	{
+		XMLTag:           "BookerID",
+		FirstCharsToShow: 0,
+		LastCharsToShow:  2,
+		KeepSameLength:   true,
+	},
+	{
+		XMLTag:           "Phone",
+		FirstCharsToShow: 4,
+		LastCharsToShow:  3,
+		CharsToIgnore:    []rune{'$'},
+		KeepSameLength:   true,
+	},
+	{
+		XMLTag:           "UserID1",
+		FirstCharsToShow: 5,
+		LastCharsToShow:  6,
+		CharsToIgnore:    []rune{'@'},
+		RestrictionType:  logger.MaskRestrictionTypeEmail,
+		KeepSameLength:   true,
+	},
+	{
+		XMLTag:           "UserID2",
+		FirstCharsToShow: 5,
+		LastCharsToShow:  6,
+		CharsToIgnore:    []rune{'@'},
+		RestrictionType:  logger.MaskRestrictionTypeEmail,
+		KeepSameLength:   true,
+	},
+}
+
+var jsonMaskData = []logger.MaskData{
+	{
+		JSONKeys:         []string{"source", "phone", "number"},
+		FirstCharsToShow: 2,
+		LastCharsToShow:  4,
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"yo"},
+		FirstCharsToShow: 4,
+		LastCharsToShow:  6,
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"destination", "phone", "number"},
+		FirstCharsToShow: 2,
+		LastCharsToShow:  4,
+		CharsToIgnore:    []rune{'+'},
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"test1"},
+		FirstCharsToShow: 0,
+		LastCharsToShow:  0,
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"test2"},
+		FirstCharsToShow: 2,
+		LastCharsToShow:  0,
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"test3"},
+		FirstCharsToShow: 0,
+		LastCharsToShow:  3,
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"test4"},
+		FirstCharsToShow: 3,
+		LastCharsToShow:  3,
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"test5", "[]", "customer", "name"},
+		FirstCharsToShow: 2,
+		LastCharsToShow:  3,
+		KeepSameLength:   false,
+	},
+	{
+		JSONKeys:         []string{"test5", "[]", "customer", "email"},
+		FirstCharsToShow: 2,
+		LastCharsToShow:  3,
+		CharsToIgnore:    []rune{'@'},
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"test6", "[]", "nested", "[]", "value"},
+		FirstCharsToShow: 1,
+		LastCharsToShow:  1,
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"test7", "[]"},
+		FirstCharsToShow: 1,
+		LastCharsToShow:  1,
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"customer", "email"},
+		FirstCharsToShow: 5,
+		LastCharsToShow:  3,
+		CharsToIgnore:    []rune{'@'},
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"shipping", "phone", "number"},
+		FirstCharsToShow: 2,
+		LastCharsToShow:  1,
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"risk", "userId1"},
+		FirstCharsToShow: 2,
+		LastCharsToShow:  7,
+		CharsToIgnore:    []rune{'@'},
+		KeepSameLength:   true,
+	},
+	{
+		JSONKeys:         []string{"risk", "userId2"},
+		FirstCharsToShow: 2,
+		LastCharsToShow:  7,
+		RestrictionType:  logger.MaskRestrictionTypeEmail,
+		CharsToIgnore:    []rune{'@'},
+		KeepSameLength:   true,
+	},
+}
+
+func Test_MaskJSONStream_SameAsMaskJSON(t *testing.T) {
+	input, err := parseJSONToString("mask_input.json")
+	assert.NoError(t, err)
+
+	selectorMasks := []logger.MaskData{
+		{JSONKeys: []string{"$..number"}, FirstCharsToShow: 2, LastCharsToShow: 2, KeepSameLength: true},
+		{JSONKeys: []string{"$../(?i)^email$/"}, FirstCharsToShow: 1, LastCharsToShow: 4},
+		{JSONKeys: []string{"$.test6[*].nested[0].value"}, LastCharsToShow: 1, KeepSameLength: true},
+	}
+
+	for name, test := range map[string]struct {
+		input    string
+		maskChar string
+		toMasks  []logger.MaskData
+	}{
+		"key paths":         {input: input, maskChar: "|", toMasks: jsonMaskData},
+		"default mask char": {input: input, toMasks: jsonMaskData},
+		"selectors":         {input: input, maskChar: "*", toMasks: selectorMasks},
+		"escaped & unicode values": {
+			input:   `{"a":"x\"y\\z\n","b":"café <&>","c":[1.50,-2e3,true,null,{}]}`,
+			toMasks: []logger.MaskData{{JSONKeys: []string{"b"}, LastCharsToShow: 2}},
+		},
+		"array root": {
+			input:   `[{"a":"secret"},{"a":"public"}]`,
+			toMasks: []logger.MaskData{{JSONKeys: []string{"[]", "a"}, FirstCharsToShow: 1}},
+		},
+		"scalar root": {input: `"secret"`, toMasks: []logger.MaskData{{JSONKeys: []string{"a"}}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var out bytes.Buffer
+			err := logger.MaskJSONStream(&out, strings.NewReader(test.input), test.maskChar, test.toMasks)
+			assert.NoError(err)
+			assert.Equal(logger.MaskJSON(test.input, test.maskChar, test.toMasks), out.String())
+		})
+	}
+}
+
+func Test_MaskJSONStream_TruncatesInvalidInput(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	err := logger.MaskJSONStream(&out, strings.NewReader(`{"email":"john@wego.com","phone":}`), "*",
+		[]logger.MaskData{{JSONKeys: []string{"email"}, FirstCharsToShow: 2}})
+	assert.ErrorContains(err, "invalid JSON input")
+	assert.Equal(`{"email":"jo*","phone":...[truncated]`, out.String())
+
+	out.Reset()
+	err = logger.MaskJSONStream(&out, strings.NewReader(`{"a":["b"`), "*", nil)
+	assert.ErrorContains(err, "invalid JSON input")
+	assert.Equal(`{"a":["b"...[truncated]`, out.String())
+
+	out.Reset()
+	err = logger.MaskJSONStream(&out, strings.NewReader(`{}`), "*", []logger.MaskData{{JSONKeys: []string{"$.["}}})
+	assert.ErrorContains(err, "invalid JSON selector")
+	assert.Empty(out.String())
+}
+
+func Test_MaskXMLStream_SameAsMaskXML(t *testing.T) {
+	file, err := os.ReadFile(testFileDir + "mask_input.xml")
+	assert.NoError(t, err)
+	input := string(file)
+
+	for name, test := range map[string]struct {
+		input    string
+		maskChar string
+		toMasks  []logger.MaskData
+	}{
+		"tags":              {input: input, maskChar: "|", toMasks: xmlMaskData},
+		"default mask char": {input: input, toMasks: xmlMaskData},
+		"prefixed tags":     {input: input, toMasks: []logger.MaskData{{XMLTag: "wsa:MessageID", LastCharsToShow: 4}}},
+		"without declaration, cdata & comments": {
+			input: `<a xml:space="default"><b> x &amp; y </b><c><![CDATA[secret <1>]]></c><d>se<!--x-->cret</d>` +
+				`<e><f>nested</f></e><g/></a>`,
+			toMasks: []logger.MaskData{
+				{XMLTag: "b", FirstCharsToShow: 1},
+				{XMLTag: "c", LastCharsToShow: 2, KeepSameLength: true},
+				{XMLTag: "d", FirstCharsToShow: 2},
+				{XMLTag: "f", FirstCharsToShow: 2, KeepSameLength: true},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var out bytes.Buffer
+			err := logger.MaskXMLStream(&out, strings.NewReader(test.input), test.maskChar, test.toMasks)
+			assert.NoError(err)
+			assert.Equal(logger.MaskXML(test.input, test.maskChar, test.toMasks), out.String())
+		})
+	}
+}
+
+func Test_MaskXMLStream_TruncatesInvalidInput(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	err := logger.MaskXMLStream(&out, strings.NewReader(`<a><b>public</b><c>secret</d></a>`), "*",
+		[]logger.MaskData{{XMLTag: "c"}})
+	assert.ErrorContains(err, "invalid XML input")
+	assert.Equal(`<a><b>public</b><c>...[truncated]`, out.String())
+
+	out.Reset()
+	err = logger.MaskXMLStream(&out, strings.NewReader(`<a><b>public</b>`), "*", nil)
+	assert.ErrorContains(err, "invalid XML input")
+	assert.Equal(`<a><b>public</b>...[truncated]`, out.String())
+
+	out.Reset()
+	err = logger.MaskXMLStream(&out, strings.NewReader(`<a/>`), "*", []logger.MaskData{{XMLTag: "//a[@b]"}})
+	assert.ErrorContains(err, "XPath is not supported")
+	assert.Empty(out.String())
+}
+
+func Test_MaskStream_Differential(t *testing.T) {
+	jsonInputs := []string{
+		`{"name":"caf\u0065","url":"https:\/\/wego.com","caf\u00e9":"cr\u00e8me br\u00fbl\u00e9e"}`,
+		`{"a":{"name":"\u0041lice \"A\"","tabs":"a\tb"},"b":[{"name":"smile \ud83d\ude00"},"\u00e9"]}`,
+		`{"k\"ey":{"name":"x\\y"},"list":["one","\u0074wo"],"n":1.0e+2,"name":""}`,
+		` [ {"name" : "spaced"} , "x" , 1 ] `,
+	}
+	jsonMasks := map[string][]logger.MaskData{
+		"key paths": {
+			{JSONKeys: []string{"name"}, FirstCharsToShow: 1, LastCharsToShow: 1},
+			{JSONKeys: []string{"a", "name"}, FirstCharsToShow: 2, KeepSameLength: true},
+			{JSONKeys: []string{"list", "[]"}, LastCharsToShow: 1},
+		},
+		"selectors": {
+			{JSONKeys: []string{"$..name"}, FirstCharsToShow: 2, LastCharsToShow: 1, KeepSameLength: true},
+			{JSONKeys: []string{`$../(?i)^caf/`}, LastCharsToShow: 3},
+		},
+		"none": nil,
+	}
+	for name, toMasks := range jsonMasks {
+		for i, input := range jsonInputs {
+			t.Run(fmt.Sprintf("JSON %s %d", name, i), func(t *testing.T) {
+				var out bytes.Buffer
+				assert.NoError(t, logger.MaskJSONStream(&out, strings.NewReader(input), "*", toMasks))
+				assert.Equal(t, logger.MaskJSON(input, "*", toMasks), out.String())
+			})
+		}
+	}
+
+	xmlInputs := []string{
+		`<Card><Number>4111111111111111</Number><Holder><Name>John</Name><Name>Doe</Name></Holder></Card>`,
+		`<a><Card>  <Number>4111 1111</Number>  <!--c--><Expiry>12/30</Expiry></Card><Card/></a>`,
+		`<a><Card>pre<Number><![CDATA[4111<>]]></Number>post</Card><Number>5500</Number></a>`,
+		`<a xml:space="default"><Card> <Card> 4111 </Card> </Card><Name> Jane &amp; Co </Name></a>`,
+	}
+	xmlMasks := map[string][]logger.MaskData{
+		"outer first": {
+			{XMLTag: "Card", FirstCharsToShow: 2, LastCharsToShow: 2},
+			{XMLTag: "Number", LastCharsToShow: 4, KeepSameLength: true},
+		},
+		"inner first": {
+			{XMLTag: "Number", LastCharsToShow: 4, KeepSameLength: true},
+			{XMLTag: "Name", FirstCharsToShow: 1},
+			{XMLTag: "Card", FirstCharsToShow: 2, LastCharsToShow: 2},
+		},
+		"children only": {
+			{XMLTag: "Holder", FirstCharsToShow: 1, LastCharsToShow: 1},
+			{XMLTag: "Expiry"},
+		},
+	}
+	for name, toMasks := range xmlMasks {
+		for i, input := range xmlInputs {
+			t.Run(fmt.Sprintf("XML %s %d", name, i), func(t *testing.T) {
+				var out bytes.Buffer
+				assert.NoError(t, logger.MaskXMLStream(&out, strings.NewReader(input), "*", toMasks))
+				assert.Equal(t, logger.MaskXML(input, "*", toMasks), out.String())
+			})
+		}
+	}
+}
+
+func Test_MaskXMLStream_MasksTagsWithElements(t *testing.T) {
+	var out bytes.Buffer
+	err := logger.MaskXMLStream(&out, strings.NewReader(`<a><Card><Number>4111111111111111</Number></Card></a>`),
+		"*", []logger.MaskData{{XMLTag: "Card", LastCharsToShow: 4, KeepSameLength: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, `<a><Card>************1111</Card></a>`, out.String())
+}
+
+func largeJSON(items int) string {
+	var b strings.Builder
+	b.WriteString(`{"search_id":"abc123","passengers":[`)
+	for i := 0; i < items; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"name":"passenger %d","email":"passenger%d@wego.com","documents":[{"type":"passport",`+
+			`"number":"A%07d","expiry":"2030-01-01"}],"fare":{"amount":%d.5,"currency":"SGD"}}`, i, i, i, i)
+	}
+	b.WriteString(`]}`)
+	return b.String()
+}
+
+func largeXML(items int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><OrderViewRS><Passengers>`)
+	for i := 0; i < items; i++ {
+		fmt.Fprintf(&b, `<Passenger ID="P%d"><Name>passenger %d</Name><Email>passenger%d@wego.com</Email>`+
+			`<Document Type="PT"><Number>A%07d</Number><Expiry>2030-01-01</Expiry></Document></Passenger>`, i, i, i, i)
+	}
+	b.WriteString(`</Passengers></OrderViewRS>`)
+	return b.String()
+}
+
+var (
+	largeJSONMasks = []logger.MaskData{
+		{JSONKeys: []string{"passengers", "[]", "email"}, FirstCharsToShow: 2, LastCharsToShow: 4},
+		{JSONKeys: []string{"passengers", "[]", "documents", "[]", "number"}, LastCharsToShow: 2},
+	}
+	largeXMLMasks = []logger.MaskData{
+		{XMLTag: "Email", FirstCharsToShow: 2, LastCharsToShow: 4},
+		{XMLTag: "Number", LastCharsToShow: 2},
+	}
+)
+
+func BenchmarkMaskJSON_Large(b *testing.B) {
+	input := largeJSON(10000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = logger.MaskJSON(input, "*", largeJSONMasks)
+	}
+}
+
+func BenchmarkMaskJSONStream_Large(b *testing.B) {
+	input := largeJSON(10000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = logger.MaskJSONStream(io.Discard, strings.NewReader(input), "*", largeJSONMasks)
+	}
+}
+
+func BenchmarkMaskXML_Large(b *testing.B) {
+	input := largeXML(10000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = logger.MaskXML(input, "*", largeXMLMasks)
+	}
+}
+
+func BenchmarkMaskXMLStream_Large(b *testing.B) {
+	input := largeXML(10000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = logger.MaskXMLStream(io.Discard, strings.NewReader(input), "*", largeXMLMasks)
+	}
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"context"
+	"strconv"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// the keys of the trace correlation
+const (
+	// the keys of the Datadog log correlation, with the decimal IDs
+	traceKeyDDTraceID = "dd.trace_id"
+	traceKeyDDSpanID  = "dd.span_id"
+	// traceKeyDDTraceIDUpper is the hex of the upper 64 bits of the 128 bits Datadog trace IDs, named after the span tag
+	traceKeyDDTraceIDUpper = "_dd.p.tid"
+	// the keys of the OpenTelemetry log data model, with the hex IDs
+	traceKeyTraceID = "trace_id"
+	traceKeySpanID  = "span_id"
+)
+
+// zeroTraceIDUpper is the upper 64 bits of the 64 bits Datadog trace IDs
+const zeroTraceIDUpper = "0000000000000000"
+
+// traceContext is the IDs of the active span of a context
+type traceContext struct {
+	ddTraceID, ddSpanID string
+	ddTraceIDUpper      string
+	traceID, spanID     string
+}
+
+// traceOf returns the IDs of the active Datadog span & OpenTelemetry span of the context
+func traceOf(ctx context.Context) (t traceContext) {
+	if ctx == nil {
+		return
+	}
+
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		t.ddTraceID = strconv.FormatUint(span.Context().TraceID(), 10)
+		t.ddSpanID = strconv.FormatUint(span.Context().SpanID(), 10)
+		if sc, ok := span.Context().(ddtrace.SpanContextW3C); ok {
+			if traceID := sc.TraceID128(); len(traceID) == 32 && traceID[:16] != zeroTraceIDUpper {
+				t.ddTraceIDUpper = traceID[:16]
+			}
+		}
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		t.traceID = sc.TraceID().String()
+		t.spanID = sc.SpanID().String()
+	}
+	return
+}
+
+func (t traceContext) isZero() bool {
+	return t == traceContext{}
+}
+
+func (t traceContext) fields() []zap.Field {
+	var fields []zap.Field
+	if t.ddTraceID != "" {
+		fields = append(fields, zap.String(traceKeyDDTraceID, t.ddTraceID), zap.String(traceKeyDDSpanID, t.ddSpanID))
+	}
+	if t.ddTraceIDUpper != "" {
+		fields = append(fields, zap.String(traceKeyDDTraceIDUpper, t.ddTraceIDUpper))
+	}
+	if t.traceID != "" {
+		fields = append(fields, zap.String(traceKeyTraceID, t.traceID), zap.String(traceKeySpanID, t.spanID))
+	}
+	return fields
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wego/pkg/collection"
+	"github.com/wego/pkg/common"
+	httpHeader "github.com/wego/pkg/http/header"
+)
+
+// TransportRule is how the calls to a host & path are recorded
+type TransportRule struct {
+	// Host is the host of the calls, e.g. api.partner.com, the rule matches all hosts when it's empty
+	Host string
+	// PathPrefix is the prefix of the path of the calls, the rule matches all paths when it's empty
+	PathPrefix string
+	RouteRule
+}
+
+/*
+Transport is a http.RoundTripper recording each outbound call as a Request, which is logged by
+LogPartnerRequestContext with the span of the request context. The type of the Request is taken from
+RequestTypeFromContext & its basics from common.GetBasics of the request context:
+
+	client := &http.Client{Transport: &logger.Transport{Rules: rules}}
+	ctx := logger.ContextWithRequestType(ctx, "partner_search")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	res, err := client.Do(req)
+
+The bodies are teed while they are read by the base transport & the caller, up to MaxBodySize, so the call is logged
+when the response body is closed. The duration is the time until the response headers are received.
+*/
+type Transport struct {
+	// Base is the transport making the calls, http.DefaultTransport is used when it's nil
+	Base http.RoundTripper
+	// MaxBodySize is the number of bytes of the request & response bodies to record, 64KB is used when it's 0.
+	// The truncated bodies are recorded with a truncated marker, or as the marker only when they have rules to apply.
+	MaxBodySize int
+	// MaskChar is the char to mask the bodies with, the default mask char is used when it's empty
+	MaskChar string
+	// Replacement is the replacement of the redacted values, the default replacement is used when it's empty
+	Replacement string
+	// Rules are the rules of the calls, the first matching rule is applied
+	Rules []TransportRule
+}
+
+// RoundTrip makes the call with the base transport & records it
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	rule := t.rule(r)
+	if rule.Skip {
+		return base.RoundTrip(r)
+	}
+
+	ctx := r.Context()
+	reqType := r.Header.Get(httpHeader.ContentType)
+	req := &Request{
+		Type:           RequestTypeFromContext(ctx),
+		Method:         r.Method,
+		URL:            rule.url(r.URL),
+		RequestHeaders: rule.Policy.ApplyHeaders(headersOf(r.Header)),
+		RequestedAt:    time.Now(),
+	}
+	if basics := common.GetBasics(ctx); len(basics) > 0 {
+		req.Basics = make(common.Basics, len(basics))
+		collection.Copy(req.Basics, basics)
+	}
+
+	reqBody := &cappedBuffer{max: t.maxBodySize()}
+	if r.Body != nil && r.Body != http.NoBody {
+		// the request must not be modified by a RoundTripper
+		r = r.Clone(ctx)
+		r.Body = teeReadCloser{Reader: io.TeeReader(r.Body, reqBody), Closer: r.Body}
+	}
+
+	res, err := base.RoundTrip(r)
+	req.Duration = time.Since(req.RequestedAt)
+	if err != nil {
+		req.Error = err
+		req.RequestBody = reqBody.record(reqType, rule.requestRules(t.MaskChar, t.Replacement))
+		LogPartnerRequestContext(ctx, req)
+		return nil, err
+	}
+
+	req.StatusCode = int32(res.StatusCode)
+	req.ResponseHeaders = rule.Policy.ApplyHeaders(headersOf(res.Header))
+
+	resBody := &cappedBuffer{max: t.maxBodySize()}
+	resType := res.Header.Get(httpHeader.ContentType)
+	log := func() {
+		req.RequestBody = reqBody.record(reqType, rule.requestRules(t.MaskChar, t.Replacement))
+		req.ResponseBody = resBody.record(resType, rule.responseRules(t.MaskChar, t.Replacement))
+		LogPartnerRequestContext(ctx, req)
+	}
+
+	if res.Body == nil || res.Body == http.NoBody {
+		log()
+		return res, nil
+	}
+	res.Body = &loggedBody{ReadCloser: res.Body, copy: resBody, log: log}
+	return res, nil
+}
+
+func (t *Transport) maxBodySize() int {
+	if t.MaxBodySize <= 0 {
+		return defaultMaxBodySize
+	}
+	return t.MaxBodySize
+}
+
+func (t *Transport) rule(r *http.Request) RouteRule {
+	for _, rule := range t.Rules {
+		if (rule.Host == "" || strings.EqualFold(rule.Host, r.URL.Hostname())) &&
+			strings.HasPrefix(r.URL.Path, rule.PathPrefix) {
+			return rule.RouteRule
+		}
+	}
+	return RouteRule{}
+}
+
+// loggedBody copies the response body while it's read, & logs the call once it's closed
+type loggedBody struct {
+	io.ReadCloser
+	copy *cappedBuffer
+	log  func()
+	once sync.Once
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	_, _ = b.copy.Write(p[:n])
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.log)
+	return err
+}
@@ -0,0 +1,177 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/common"
+	"github.com/wego/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+func initPartnerRequestLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	err := logger.Init(logger.Config{
+		UltronEx:       stdout,
+		PartnerRequest: logger.Sink{WriteSyncer: zapcore.AddSync(&buf)},
+		Request:        stdout,
+		PromoCodeEvent: stdout,
+	})
+	assert.NoError(t, err)
+	return &buf
+}
+
+func newPartnerServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"echo":` + string(body) + `,"token":"secret"}`))
+	}))
+}
+
+func Test_Transport(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initPartnerRequestLog(t)
+	server := newPartnerServer()
+	defer server.Close()
+
+	client := &http.Client{Transport: &logger.Transport{
+		Rules: []logger.TransportRule{
+			{PathPrefix: "/health", RouteRule: logger.RouteRule{Skip: true}},
+			{
+				PathPrefix: "/bookings",
+				RouteRule: logger.RouteRule{
+					RequestRedacts:  [][]string{{"card_number"}},
+					ResponseRedacts: [][]string{{"token"}, {"echo", "card_number"}},
+				},
+			},
+		},
+	}}
+
+	ctx := logger.ContextWithRequestType(context.Background(), requestType)
+	ctx = common.SetBasic(ctx, "booking_id", "123")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/bookings", strings.NewReader(`{"card_number":"4111"}`))
+	res, err := client.Do(req)
+	if !assert.NoError(err) {
+		return
+	}
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(`{"echo":{"card_number":"4111"},"token":"secret"}`, string(body))
+	assert.NoError(res.Body.Close())
+
+	logger.Sync()
+	var record map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(string(requestType), record["type"])
+	assert.Equal(server.URL+"/bookings", record["url"])
+	assert.Equal(`"123"`, record["booking_id"])
+	assert.Equal(float64(http.StatusAccepted), record["status_code"])
+	assert.Equal(`{"card_number":"[Filtered by Wego]"}`, record["request_body"])
+	assert.Equal(`{"echo":{"card_number":"[Filtered by Wego]"},"token":"[Filtered by Wego]"}`, record["response_body"])
+
+	// skipped calls are not logged
+	buf.Reset()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/health", nil)
+	res, err = client.Do(req)
+	if assert.NoError(err) {
+		_ = res.Body.Close()
+	}
+	logger.Sync()
+	assert.Empty(buf.String())
+}
+
+func Test_Transport_Policy(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initPartnerRequestLog(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		_, _ = w.Write([]byte(`<Payment><CardNumber>` + r.PostForm.Get("card_number") + `</CardNumber></Payment>`))
+	}))
+	defer server.Close()
+
+	policy := &logger.MaskingPolicy{Rules: []logger.MaskingRule{{
+		FormKeys:    []string{"card_number"},
+		XMLTags:     []string{"CardNumber"},
+		QueryParams: []string{"api_key"},
+		Headers:     []string{"X-Partner-Key"},
+	}}}
+	client := &http.Client{Transport: &logger.Transport{
+		Rules: []logger.TransportRule{{RouteRule: logger.RouteRule{Policy: policy}}},
+	}}
+
+	ctx := logger.ContextWithRequestType(context.Background(), requestType)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/payments?api_key=key&site=sg",
+		strings.NewReader("card_number=4111111111111111&currency=SGD"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Partner-Key", "key")
+	res, err := client.Do(req)
+	if !assert.NoError(err) {
+		return
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	logger.Sync()
+	var record map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(server.URL+"/payments?api_key=%5BFiltered+by+Wego%5D&site=sg", record["url"])
+	assert.Contains(record["request_headers"], map[string]any{"name": "X-Partner-Key", "value": "[Filtered by Wego]"})
+	assert.Equal("card_number=%5BFiltered+by+Wego%5D&currency=SGD", record["request_body"])
+	assert.Equal("<Payment><CardNumber>[Filtered by Wego]</CardNumber></Payment>", record["response_body"])
+	assert.NotContains(buf.String(), "4111111111111111")
+}
+
+func Test_Transport_Truncated(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initPartnerRequestLog(t)
+	server := newPartnerServer()
+	defer server.Close()
+
+	client := &http.Client{Transport: &logger.Transport{MaxBodySize: 8}}
+	ctx := logger.ContextWithRequestType(context.Background(), requestType)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(`"partner"`))
+	res, err := client.Do(req)
+	if !assert.NoError(err) {
+		return
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	logger.Sync()
+	var record map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(`"partner...[truncated]`, record["request_body"])
+	assert.Equal(`{"echo":...[truncated]`, record["response_body"])
+}
+
+func Test_Transport_Error(t *testing.T) {
+	assert := assert.New(t)
+
+	buf := initPartnerRequestLog(t)
+	server := newPartnerServer()
+	server.Close()
+
+	client := &http.Client{Transport: &logger.Transport{}}
+	ctx := logger.ContextWithRequestType(context.Background(), requestType)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	_, err := client.Do(req)
+	assert.Error(err)
+
+	logger.Sync()
+	var record map[string]any
+	assert.NoError(json.Unmarshal(buf.Bytes(), &record))
+	assert.Contains(record["error"], "connection refused")
+}
@@ -1,24 +1,204 @@
 package logger
 
 import (
+	"strings"
+
 	"go.uber.org/zap/zapcore"
 )
 
+// Severity is the severity of an UltronEx message
+type Severity int
+
+// severities
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+// String returns the name of the severity
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// color returns the color of the attachments of the severity
+func (s Severity) color() string {
+	switch {
+	case s >= SeverityCritical:
+		return "#8b0000"
+	case s == SeverityError:
+		return "danger"
+	case s == SeverityWarning:
+		return "warning"
+	default:
+		return "#439fe0"
+	}
+}
+
 // UltronExMsg ...
 type UltronExMsg struct {
+	// Channel is the channel of the message, the channel is routed by the UltronEx client when it's empty
 	Channel string
 	Text    string
+	// Payload is shown in a code block, in the first attachment of the webhook messages
 	Payload string
-	Title   string
+	// Title is the title of the first attachment of the webhook messages
+	Title       string
+	Severity    Severity
+	RequestType RequestType
+	// Fields are shown in the first attachment of the webhook messages
+	Fields      []UltronExField
+	Attachments []UltronExAttachment
+}
+
+// UltronExAttachment is an attachment of an UltronEx message, the webhook attachments are colored by the severity of
+// the message
+type UltronExAttachment struct {
+	Title string
+	Text  string
+	// Payload is shown in a code block
+	Payload string
+	Fields  []UltronExField
+}
+
+// UltronExField is a title & value shown in a table, Short fields are shown side by side
+type UltronExField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short,omitempty"`
 }
 
 // MarshalLogObject marshal UltronExMsg to zap log object
 // The struct need to implement this, so we can log it with zap.Object
 func (m UltronExMsg) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	title := "\n>" + m.Title + "\n"
-	payload := "```" + m.Payload + "```"
+	payload := "```" + escapeBackticks(m.Payload) + "```"
 
 	enc.AddString("channel", m.Channel)
-	enc.AddString("text", m.Text+title+payload)
+	enc.AddString("text", m.Text+title+payload+m.sectionsText())
 	return nil
 }
+
+// sectionsText returns the fields & the attachments of the message in the text layout of the UltronEx file, a line
+// per field & a quoted title, a text & a payload per attachment. The payloads are escaped by escapeBackticks.
+func (m UltronExMsg) sectionsText() string {
+	var b strings.Builder
+	writeUltronExFields(&b, m.Fields)
+	for _, a := range m.Attachments {
+		b.WriteString("\n>" + a.Title + "\n")
+		if a.Text != "" {
+			b.WriteString(a.Text + "\n")
+		}
+		if a.Payload != "" {
+			b.WriteString("```" + escapeBackticks(a.Payload) + "```")
+		}
+		writeUltronExFields(&b, a.Fields)
+	}
+	return b.String()
+}
+
+func writeUltronExFields(b *strings.Builder, fields []UltronExField) {
+	for _, field := range fields {
+		b.WriteString("\n" + field.Title + ": " + field.Value)
+	}
+}
+
+// formattedUltronExMsg is an UltronEx message as posted by UltronExWebhookTransport, with its texts escaped & its
+// title, payload & fields in attachments
+type formattedUltronExMsg struct {
+	Channel     string                        `json:"channel"`
+	Severity    string                        `json:"severity"`
+	RequestType string                        `json:"request_type,omitempty"`
+	Text        string                        `json:"text"`
+	Attachments []formattedUltronExAttachment `json:"attachments,omitempty"`
+}
+
+type formattedUltronExAttachment struct {
+	Title  string          `json:"title,omitempty"`
+	Text   string          `json:"text,omitempty"`
+	Color  string          `json:"color"`
+	Fields []UltronExField `json:"fields,omitempty"`
+}
+
+func (m UltronExMsg) format() formattedUltronExMsg {
+	f := formattedUltronExMsg{
+		Channel:     m.Channel,
+		Severity:    m.Severity.String(),
+		RequestType: string(m.RequestType),
+		Text:        escapeUltronEx(m.Text),
+	}
+
+	color := m.Severity.color()
+	if m.Title != "" || m.Payload != "" || len(m.Fields) > 0 {
+		f.Attachments = append(f.Attachments, UltronExAttachment{
+			Title:   m.Title,
+			Payload: m.Payload,
+			Fields:  m.Fields,
+		}.format(color))
+	}
+	for _, a := range m.Attachments {
+		f.Attachments = append(f.Attachments, a.format(color))
+	}
+	return f
+}
+
+func (a UltronExAttachment) format(color string) formattedUltronExAttachment {
+	texts := make([]string, 0, 2)
+	if a.Text != "" {
+		texts = append(texts, escapeUltronEx(a.Text))
+	}
+	if a.Payload != "" {
+		texts = append(texts, codeBlock(a.Payload))
+	}
+
+	f := formattedUltronExAttachment{
+		Title: escapeUltronEx(a.Title),
+		Text:  strings.Join(texts, "\n"),
+		Color: color,
+	}
+	if len(a.Fields) > 0 {
+		f.Fields = make([]UltronExField, len(a.Fields))
+		for i, field := range a.Fields {
+			f.Fields[i] = UltronExField{
+				Title: escapeUltronEx(field.Title),
+				Value: escapeUltronEx(field.Value),
+				Short: field.Short,
+			}
+		}
+	}
+	return f
+}
+
+var ultronExEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeUltronEx escapes the control characters of the markdown of the messages, so a text cannot create links or
+// mentions
+func escapeUltronEx(s string) string {
+	return ultronExEscaper.Replace(s)
+}
+
+// codeBlock returns the text escaped in a code block
+func codeBlock(s string) string {
+	return "```\n" + escapeBackticks(escapeUltronEx(s)) + "\n```"
+}
+
+// escapeBackticks adds a zero-width space in each triple backtick of the text, so it cannot close a code block
+func escapeBackticks(s string) string {
+	// a longer run of backticks may still have a triple backtick after a replacement, e.g. 6 backticks
+	for strings.Contains(s, "```") {
+		s = strings.ReplaceAll(s, "```", "``\u200b`")
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+func initUltronExLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	stdout := logger.Sink{Type: logger.SinkStdout}
+	err := logger.Init(logger.Config{
+		UltronEx:       logger.Sink{WriteSyncer: zapcore.AddSync(&buf)},
+		PartnerRequest: stdout,
+		Request:        stdout,
+		PromoCodeEvent: stdout,
+	})
+	assert.NoError(t, err)
+	return &buf
+}
+
+func Test_LogUltronEx(t *testing.T) {
+	tests := map[string]struct {
+		msg      logger.UltronExMsg
+		expected map[string]any
+	}{
+		"title & payload": {
+			msg: logger.UltronExMsg{
+				Channel:     "bookings",
+				Text:        "Booking <failed> & retried",
+				Title:       "POST /bookings",
+				Payload:     "{\"note\":\"`quoted`\"}",
+				Severity:    logger.SeverityError,
+				RequestType: requestType,
+			},
+			expected: map[string]any{
+				"channel": "bookings",
+				"text":    "Booking <failed> & retried\n>POST /bookings\n```{\"note\":\"`quoted`\"}```",
+			},
+		},
+		"payloads with backticks": {
+			msg: logger.UltronExMsg{
+				Channel:     "bookings",
+				Text:        "Booking failed",
+				Title:       "POST /bookings",
+				Payload:     "a```b",
+				Attachments: []logger.UltronExAttachment{{Title: "Response", Payload: "c``````d"}},
+			},
+			expected: map[string]any{
+				"channel": "bookings",
+				"text":    "Booking failed\n>POST /bookings\n```a``\u200b`b```\n>Response\n```c``\u200b``\u200b`\u200b`d```",
+			},
+		},
+		"fields & attachments": {
+			msg: logger.UltronExMsg{
+				Channel:     "bookings",
+				Text:        "Booking failed",
+				Title:       "POST /bookings",
+				Payload:     "{}",
+				Fields:      []logger.UltronExField{{Title: "Partner", Value: "conferma", Short: true}},
+				Attachments: []logger.UltronExAttachment{{Title: "Response", Text: "500", Payload: "error"}},
+			},
+			expected: map[string]any{
+				"channel": "bookings",
+				"text":    "Booking failed\n>POST /bookings\n```{}```\nPartner: conferma\n>Response\n500\n```error```",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			buf := initUltronExLog(t)
+			logger.LogUltronEx(&test.msg)
+			logger.Sync()
+
+			var log struct {
+				Msg map[string]any `json:"msg"`
+			}
+			assert.NoError(json.Unmarshal(buf.Bytes(), &log))
+			assert.Equal(test.expected, log.Msg)
+		})
+	}
+}
+
+func Test_Severity_String(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("info", logger.SeverityInfo.String())
+	assert.Equal("warning", logger.SeverityWarning.String())
+	assert.Equal("error", logger.SeverityError.String())
+	assert.Equal("critical", logger.SeverityCritical.String())
+	assert.Equal("unknown", logger.Severity(10).String())
+}
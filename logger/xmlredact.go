@@ -0,0 +1,380 @@
+package logger
+
+import (
+	"encoding/xml"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/wego/pkg/errors"
+)
+
+// XMLTarget is the text or an attribute of the elements to redact
+type XMLTarget struct {
+	/*
+		Tag is the name of the elements, which is matched:
+		  - by the namespace URI & the local name when it's in the form of {uri}name, e.g. {http://cpapi.conferma.com/}Number
+		  - by the prefix & the local name when it has a prefix, e.g. wsse:Password
+		  - by the local name in any namespace otherwise, * matches all the elements
+	*/
+	Tag string
+	// Attr is the name of the attribute to redact, matched like Tag, the text of the elements is redacted when it's empty
+	Attr string
+}
+
+/*
+RedactXMLTargets replaces the text or the attributes of the targets in the input XML with replacement or
+defaultReplacement when replacement is empty.
+
+Only the bytes of the redacted text nodes & attribute values are replaced, so the rest of the document, including the
+whitespaces, the namespace prefixes & the declaration, is kept as is. The text of an element is all the non-blank text
+nodes in it, including the ones of the nested elements.
+*/
+func RedactXMLTargets(xml, replacement string, targets []XMLTarget) string {
+	out, err := redactXMLBytes(xml, replacementCharOrDefault(replacement), &xmlMatcher{targets: targets})
+	if err != nil {
+		return errors.New("invalid XML input", err).Error()
+	}
+	return out
+}
+
+// xmlElementStart is an element found by the redaction engine
+type xmlElementStart struct {
+	// ordinal is the index of the element in the document order
+	ordinal int
+	name    xml.Name
+	// rawName is the name of the element as written in the document, e.g. soap:Body
+	rawName string
+}
+
+// xmlMatcher matches the elements & attributes to redact, by the targets or by the elements found by XPaths
+type xmlMatcher struct {
+	targets []XMLTarget
+	// texts are the ordinals of the elements whose text is redacted
+	texts map[int]bool
+	// attrs are the local names of the attributes to redact by the ordinals of the elements
+	attrs map[int][]string
+	// mask masks the text & the attribute values instead of replacing them, the content of a masked element is replaced
+	// by its masked inner text, see MaskXML
+	mask func(text string) string
+}
+
+// addXPath adds the elements & attributes found by an XPath in the document
+func (m *xmlMatcher) addXPath(doc *xmlquery.Node, expr string) error {
+	nodes, err := xmlquery.QueryAll(doc, expr)
+	if err != nil || len(nodes) == 0 {
+		return err
+	}
+
+	if m.texts == nil {
+		m.texts = map[int]bool{}
+		m.attrs = map[int][]string{}
+	}
+	ordinals := elementOrdinals(doc)
+	for _, node := range nodes {
+		switch node.Type {
+		case xmlquery.ElementNode:
+			m.texts[ordinals[node]] = true
+		case xmlquery.TextNode, xmlquery.CharDataNode:
+			if ordinal, ok := ordinals[node.Parent]; ok {
+				m.texts[ordinal] = true
+			}
+		case xmlquery.AttributeNode:
+			if ordinal, ok := ordinals[node.Parent]; ok {
+				m.attrs[ordinal] = append(m.attrs[ordinal], node.Data)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *xmlMatcher) matchText(el xmlElementStart) bool {
+	if m.texts[el.ordinal] {
+		return true
+	}
+	for _, target := range m.targets {
+		if target.Attr == "" && matchXMLName(target.Tag, el.name, el.rawName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *xmlMatcher) matchAttr(el xmlElementStart, attr xml.Attr, rawName string) bool {
+	for _, local := range m.attrs[el.ordinal] {
+		if local == attr.Name.Local {
+			return true
+		}
+	}
+	for _, target := range m.targets {
+		if target.Attr != "" && matchXMLName(target.Tag, el.name, el.rawName) &&
+			matchXMLName(target.Attr, attr.Name, rawName) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyAttr tells whether some attributes of the element may be redacted, whatever their names
+func (m *xmlMatcher) matchAnyAttr(el xmlElementStart) bool {
+	if len(m.attrs[el.ordinal]) > 0 {
+		return true
+	}
+	for _, target := range m.targets {
+		if target.Attr != "" && matchXMLName(target.Tag, el.name, el.rawName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *xmlMatcher) hasAttrs() bool {
+	if len(m.attrs) > 0 {
+		return true
+	}
+	for _, target := range m.targets {
+		if target.Attr != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func matchXMLName(pattern string, name xml.Name, rawName string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasPrefix(pattern, "{"):
+		uri, local, found := strings.Cut(pattern[1:], "}")
+		return found && uri == name.Space && local == name.Local
+	case strings.Contains(pattern, ":"):
+		return pattern == rawName
+	default:
+		return pattern == name.Local
+	}
+}
+
+// elementOrdinals returns the indices of the elements of the document in the document order
+func elementOrdinals(doc *xmlquery.Node) map[*xmlquery.Node]int {
+	ordinals := map[*xmlquery.Node]int{}
+	var walk func(*xmlquery.Node)
+	walk = func(node *xmlquery.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == xmlquery.ElementNode {
+				ordinals[child] = len(ordinals)
+			}
+			walk(child)
+		}
+	}
+	walk(doc)
+	return ordinals
+}
+
+// xmlEdit replaces the bytes of the input from start to end with the value
+type xmlEdit struct {
+	start, end int64
+	value      string
+}
+
+// xmlOpenElement is an element being decoded by redactXMLBytes
+type xmlOpenElement struct {
+	rawName string
+	// start is the offset of the start tag & contentStart the one of the content
+	start, contentStart int64
+	// redacting tells whether the text of the element is redacted or masked
+	redacting bool
+	// whole tells the element is replaced as a whole, as its attributes to redact cannot be located
+	whole bool
+}
+
+/*
+redactXMLBytes replaces the matched text nodes & attribute values by their offsets in the input, the other bytes are
+kept as is. When the matcher masks, the content of the outermost matched elements is replaced by their masked inner
+text instead.
+
+An element whose attributes to redact cannot be located in its start tag is replaced by an element of the same name
+with the replacement as its text, so it's never written unredacted.
+*/
+func redactXMLBytes(input, replacement string, matcher *xmlMatcher) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader(input))
+	// the input is kept in its encoding, so the offsets are the ones of the input
+	dec.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+
+	value := html.EscapeString(replacement)
+	withAttrs := matcher.hasAttrs()
+	var edits []xmlEdit
+	var open []xmlOpenElement
+	// innerText is the inner text of the outermost masked element
+	var innerText strings.Builder
+	ordinal := 0
+	for {
+		start := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		end := dec.InputOffset()
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			raw := input[start:end]
+			el := xmlElementStart{ordinal: ordinal, name: tok.Name, rawName: rawTagName(raw)}
+			ordinal++
+			parentRedacting := len(open) > 0 && open[len(open)-1].redacting
+			current := xmlOpenElement{rawName: el.rawName, start: start, contentStart: end}
+			current.redacting = parentRedacting || matcher.matchText(el)
+			if current.redacting && !parentRedacting {
+				innerText.Reset()
+			}
+
+			if withAttrs {
+				var attrEdits []xmlEdit
+				attrEdits, current.whole = matcher.attrEdits(el, tok.Attr, raw, value)
+				for _, edit := range attrEdits {
+					edits = append(edits, xmlEdit{start: start + edit.start, end: start + edit.end, value: edit.value})
+				}
+			}
+			open = append(open, current)
+		case xml.EndElement:
+			current := open[len(open)-1]
+			open = open[:len(open)-1]
+			parentRedacting := len(open) > 0 && open[len(open)-1].redacting
+
+			switch {
+			case current.whole:
+				edits = append(dropEdits(edits, current.start), xmlEdit{
+					start: current.start,
+					end:   end,
+					value: "<" + current.rawName + ">" + value + "</" + current.rawName + ">",
+				})
+			case matcher.mask != nil && current.redacting && !parentRedacting &&
+				strings.TrimSpace(innerText.String()) != "":
+				edits = append(dropEdits(edits, current.contentStart), xmlEdit{
+					start: current.contentStart,
+					end:   start,
+					value: html.EscapeString(matcher.mask(innerText.String())),
+				})
+			}
+		case xml.CharData:
+			switch {
+			case len(open) == 0 || !open[len(open)-1].redacting:
+			case matcher.mask != nil:
+				innerText.Write(tok)
+			case strings.TrimSpace(string(tok)) != "":
+				edits = append(edits, xmlEdit{start: start, end: end, value: value})
+			}
+		}
+	}
+
+	if len(edits) == 0 {
+		return input, nil
+	}
+
+	var out strings.Builder
+	out.Grow(len(input))
+	var offset int64
+	for _, edit := range edits {
+		out.WriteString(input[offset:edit.start])
+		out.WriteString(edit.value)
+		offset = edit.end
+	}
+	out.WriteString(input[offset:])
+	return out.String(), nil
+}
+
+/*
+attrEdits returns the edits of the matched attributes of a start tag, by their offsets in the tag. When the attributes
+as written cannot be located in the tag while some of them may be redacted, whole is true to redact the element as a
+whole instead.
+*/
+func (m *xmlMatcher) attrEdits(el xmlElementStart, attrs []xml.Attr, tag, value string) (edits []xmlEdit, whole bool) {
+	rawAttrs := rawAttrs(tag)
+	if len(rawAttrs) != len(attrs) {
+		return nil, m.matchAnyAttr(el)
+	}
+
+	for i, attr := range attrs {
+		if !m.matchAttr(el, attr, rawAttrs[i].name) {
+			continue
+		}
+		edit := xmlEdit{start: rawAttrs[i].start, end: rawAttrs[i].end, value: value}
+		if m.mask != nil {
+			edit.value = html.EscapeString(m.mask(attr.Value))
+		}
+		edits = append(edits, edit)
+	}
+	return edits, false
+}
+
+// dropEdits drops the edits from the offset, which are in the content replaced by a later edit
+func dropEdits(edits []xmlEdit, offset int64) []xmlEdit {
+	for i, edit := range edits {
+		if edit.start >= offset {
+			return edits[:i]
+		}
+	}
+	return edits
+}
+
+// rawAttr is an attribute as written in a start tag, start & end are the offsets of its value in the tag
+type rawAttr struct {
+	name       string
+	start, end int64
+}
+
+// rawTagName returns the name of a start tag as written, e.g. soap:Body
+func rawTagName(tag string) string {
+	end := strings.IndexAny(tag, " \t\r\n/>")
+	if end < 0 {
+		return tag[1:]
+	}
+	return tag[1:end]
+}
+
+// rawAttrs returns the attributes of a start tag, which is validated by the decoder already
+func rawAttrs(tag string) []rawAttr {
+	var attrs []rawAttr
+	i := 1 + len(rawTagName(tag))
+	for {
+		i = skipXMLSpaces(tag, i)
+		if i >= len(tag) || tag[i] == '>' || tag[i] == '/' {
+			return attrs
+		}
+
+		nameStart := i
+		for i < len(tag) && tag[i] != '=' && !isXMLSpace(tag[i]) {
+			i++
+		}
+		name := tag[nameStart:i]
+		i = skipXMLSpaces(tag, i)
+		// skip =
+		i = skipXMLSpaces(tag, i+1)
+		if i >= len(tag) {
+			return attrs
+		}
+
+		quote := tag[i]
+		valueStart := i + 1
+		valueLen := strings.IndexByte(tag[valueStart:], quote)
+		if valueLen < 0 {
+			return attrs
+		}
+		attrs = append(attrs, rawAttr{name: name, start: int64(valueStart), end: int64(valueStart + valueLen)})
+		i = valueStart + valueLen + 1
+	}
+}
+
+func skipXMLSpaces(s string, i int) int {
+	for i < len(s) && isXMLSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+func isXMLSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_xmlMatcher_attrEdits_FailsClosed(t *testing.T) {
+	assert := assert.New(t)
+
+	el := xmlElementStart{name: xml.Name{Local: "Card"}, rawName: "Card"}
+	attrs := []xml.Attr{{Name: xml.Name{Local: "Number"}, Value: "4111111111111111"}}
+	// the attributes of the tag cannot be located, as if the tag was not the one decoded
+	tag := `<Card>`
+
+	matcher := &xmlMatcher{targets: []XMLTarget{{Tag: "Card", Attr: "Number"}}}
+	edits, whole := matcher.attrEdits(el, attrs, tag, defaultReplacement)
+	assert.Empty(edits)
+	assert.True(whole, "the element is redacted as a whole")
+
+	matcher = &xmlMatcher{targets: []XMLTarget{{Tag: "Holder", Attr: "Name"}}}
+	_, whole = matcher.attrEdits(el, attrs, tag, defaultReplacement)
+	assert.False(whole, "the element has no attribute to redact")
+
+	edits, whole = matcher.attrEdits(el, attrs, `<Card Number="4111111111111111">`, defaultReplacement)
+	assert.Empty(edits)
+	assert.False(whole)
+}
@@ -0,0 +1,140 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/logger"
+)
+
+const redactTargetsXML = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"
+	xmlns:wsse="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd">
+	<soap:Header>
+		<wsse:Security><wsse:Password Type='PasswordText'>s3cret</wsse:Password></wsse:Security>
+	</soap:Header>
+	<soap:Body>
+		<GetCardResponse xmlns="http://cpapi.conferma.com/">
+			<Card Number="4111156600005845" Type="VI">
+				<Number>4111156600005845</Number>
+				<Holder><First>John</First>  <Last>Doe &amp; Co</Last></Holder>
+				<CVV/>
+			</Card>
+			<Reference>4111156600005845</Reference>
+			<Note><![CDATA[s3cret]]></Note>
+		</GetCardResponse>
+	</soap:Body>
+</soap:Envelope>`
+
+func Test_RedactXMLTargets(t *testing.T) {
+	tests := map[string]struct {
+		targets  []logger.XMLTarget
+		expected string
+	}{
+		"local name in any namespace, other texts are kept": {
+			targets: []logger.XMLTarget{{Tag: "Number"}, {Tag: "Password"}},
+			expected: `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"
+	xmlns:wsse="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd">
+	<soap:Header>
+		<wsse:Security><wsse:Password Type='PasswordText'>[R]</wsse:Password></wsse:Security>
+	</soap:Header>
+	<soap:Body>
+		<GetCardResponse xmlns="http://cpapi.conferma.com/">
+			<Card Number="4111156600005845" Type="VI">
+				<Number>[R]</Number>
+				<Holder><First>John</First>  <Last>Doe &amp; Co</Last></Holder>
+				<CVV/>
+			</Card>
+			<Reference>4111156600005845</Reference>
+			<Note><![CDATA[s3cret]]></Note>
+		</GetCardResponse>
+	</soap:Body>
+</soap:Envelope>`,
+		},
+		"namespace URI, prefix, nested texts & CDATA": {
+			targets: []logger.XMLTarget{
+				{Tag: "{http://cpapi.conferma.com/}Holder"},
+				{Tag: "{http://cpapi.conferma.com/}Note"},
+				{Tag: "wsse:Password"},
+				{Tag: "soap:Password"},
+				{Tag: "{http://wrong.namespace/}Reference"},
+			},
+			expected: `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"
+	xmlns:wsse="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd">
+	<soap:Header>
+		<wsse:Security><wsse:Password Type='PasswordText'>[R]</wsse:Password></wsse:Security>
+	</soap:Header>
+	<soap:Body>
+		<GetCardResponse xmlns="http://cpapi.conferma.com/">
+			<Card Number="4111156600005845" Type="VI">
+				<Number>4111156600005845</Number>
+				<Holder><First>[R]</First>  <Last>[R]</Last></Holder>
+				<CVV/>
+			</Card>
+			<Reference>4111156600005845</Reference>
+			<Note>[R]</Note>
+		</GetCardResponse>
+	</soap:Body>
+</soap:Envelope>`,
+		},
+		"attributes": {
+			targets: []logger.XMLTarget{{Tag: "Card", Attr: "Number"}, {Tag: "*", Attr: "Type"}},
+			expected: `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"
+	xmlns:wsse="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd">
+	<soap:Header>
+		<wsse:Security><wsse:Password Type='[R]'>s3cret</wsse:Password></wsse:Security>
+	</soap:Header>
+	<soap:Body>
+		<GetCardResponse xmlns="http://cpapi.conferma.com/">
+			<Card Number="[R]" Type="[R]">
+				<Number>4111156600005845</Number>
+				<Holder><First>John</First>  <Last>Doe &amp; Co</Last></Holder>
+				<CVV/>
+			</Card>
+			<Reference>4111156600005845</Reference>
+			<Note><![CDATA[s3cret]]></Note>
+		</GetCardResponse>
+	</soap:Body>
+</soap:Envelope>`,
+		},
+		"not found": {
+			targets:  []logger.XMLTarget{{Tag: "Expiry"}, {Tag: "Card", Attr: "Expiry"}},
+			expected: redactTargetsXML,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(test.expected, logger.RedactXMLTargets(redactTargetsXML, "[R]", test.targets))
+		})
+	}
+}
+
+func Test_RedactXMLTargets_EscapesReplacement(t *testing.T) {
+	assert := assert.New(t)
+
+	output := logger.RedactXMLTargets(`<a b="1"><c>2</c></a>`, `<"&">`,
+		[]logger.XMLTarget{{Tag: "a", Attr: "b"}, {Tag: "c"}})
+	assert.Equal(`<a b="&lt;&#34;&amp;&#34;&gt;"><c>&lt;&#34;&amp;&#34;&gt;</c></a>`, output)
+}
+
+func Test_RedactXMLTargets_ReturnErrorText_WithInvalidInput(t *testing.T) {
+	assert := assert.New(t)
+
+	output := logger.RedactXMLTargets(`<a><b>1</a>`, "", []logger.XMLTarget{{Tag: "b"}})
+	assert.Contains(output, "invalid XML input")
+}
+
+func Test_RedactXML_KeepsSameTextOfOtherTags(t *testing.T) {
+	assert := assert.New(t)
+
+	output := logger.RedactXML(redactTargetsXML, "[R]", []string{"//Card/Number", "//Card/@Number"})
+	assert.Contains(output, `<Card Number="[R]" Type="VI">`)
+	assert.Contains(output, `<Number>[R]</Number>`)
+	assert.Contains(output, `<Reference>4111156600005845</Reference>`)
+	assert.Contains(output, `<?xml version="1.0" encoding="utf-8"?>`+"\n<soap:Envelope")
+}
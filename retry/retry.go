@@ -1,12 +1,14 @@
 package retry
 
 import (
-	"github.com/eapache/go-resiliency/retrier"
-	"github.com/wego/pkg/errors"
+	goErrors "errors"
 	"net"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/eapache/go-resiliency/retrier"
+	"github.com/wego/pkg/errors"
 )
 
 type retryClassifier struct{}
@@ -15,26 +17,28 @@ func (r *retryClassifier) Classify(err error) retrier.Action {
 	if err == nil {
 		return retrier.Succeed
 	}
-	switch err := err.(type) {
-	case net.Error:
+
+	var netErr net.Error
+	if goErrors.As(err, &netErr) {
 		return retrier.Retry
-	case *errors.Error:
-		if _, ok := err.Err.(net.Error); ok {
-			return retrier.Retry
-		}
-		switch errors.Code(err) {
-		case
-			int(errors.Retry),
-			http.StatusInternalServerError,
-			http.StatusBadGateway,
-			http.StatusGatewayTimeout,
-			http.StatusTooManyRequests:
-			return retrier.Retry
-		}
-	default:
+	}
+
+	var e *errors.Error
+	if !goErrors.As(err, &e) {
 		if strings.Contains(err.Error(), "error unmarshalling") {
 			return retrier.Retry
 		}
+		return retrier.Fail
+	}
+
+	switch errors.Code(e) {
+	case
+		int(errors.Retry),
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusGatewayTimeout,
+		http.StatusTooManyRequests:
+		return retrier.Retry
 	}
 	return retrier.Fail
 }
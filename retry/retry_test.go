@@ -0,0 +1,68 @@
+package retry
+
+import (
+	goErrors "errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/eapache/go-resiliency/retrier"
+	"github.com/stretchr/testify/assert"
+	"github.com/wego/pkg/errors"
+)
+
+func Test_retryClassifier_Classify(t *testing.T) {
+	timeout := &net.DNSError{Err: "i/o timeout", Name: "api.partner.com", IsTimeout: true}
+
+	tests := []struct {
+		name string
+		err  error
+		want retrier.Action
+	}{
+		{
+			name: "nil",
+			want: retrier.Succeed,
+		},
+		{
+			name: "net error",
+			err:  timeout,
+			want: retrier.Retry,
+		},
+		{
+			name: "wrapped net timeout error",
+			err:  errors.New(errors.Op("partner.Search"), fmt.Errorf("search: %w", timeout)),
+			want: retrier.Retry,
+		},
+		{
+			name: "Error wrapped by fmt",
+			err:  fmt.Errorf("search: %w", errors.New(errors.Op("partner.Search"), errors.TooManyRequests)),
+			want: retrier.Retry,
+		},
+		{
+			name: "retry kind in the chain",
+			err:  errors.New(errors.Op("booking.Create"), fmt.Errorf("create: %w", errors.New(errors.Retry))),
+			want: retrier.Retry,
+		},
+		{
+			name: "non retryable kind",
+			err:  errors.New(errors.Op("booking.Create"), errors.BadRequest),
+			want: retrier.Fail,
+		},
+		{
+			name: "unmarshalling error",
+			err:  goErrors.New("error unmarshalling response"),
+			want: retrier.Retry,
+		},
+		{
+			name: "other error",
+			err:  goErrors.New("invalid booking"),
+			want: retrier.Fail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, (&retryClassifier{}).Classify(tt.err))
+		})
+	}
+}